@@ -0,0 +1,161 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind classifies a failed call to a generation backend (the hosted diny
+// service, Ollama or an OpenAI-compatible server).
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindUnreachable
+	KindTimeout
+	KindRateLimited
+	KindServer
+	KindInvalidResponse
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindUnreachable:
+		return "network unreachable"
+	case KindTimeout:
+		return "timeout"
+	case KindRateLimited:
+		return "rate limited"
+	case KindServer:
+		return "server error"
+	case KindInvalidResponse:
+		return "invalid response"
+	default:
+		return "request failed"
+	}
+}
+
+// Error is a backend failure with enough structure to decide whether a
+// retry makes sense and to explain it to the user.
+type Error struct {
+	Kind       Kind
+	Backend    string
+	StatusCode int
+	RetryAfter time.Duration // only set for KindRateLimited
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.Backend, e.Kind)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Temporary reports whether the same request may succeed if sent again.
+func (e *Error) Temporary() bool {
+	switch e.Kind {
+	case KindUnreachable, KindTimeout, KindRateLimited, KindServer:
+		return true
+	}
+	return false
+}
+
+// Transport classifies an error returned by http.Client.Do. Cancellation is
+// passed through untouched so callers can still match context.Canceled.
+func Transport(backend string, err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	kind := KindUnreachable
+	var ne net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &ne) && ne.Timeout()) {
+		kind = KindTimeout
+	}
+	return &Error{Kind: kind, Backend: backend, Err: err}
+}
+
+// Status classifies a non-2xx response. msg is the error text the server
+// sent, if any; otherwise a generic message is used.
+func Status(backend string, resp *http.Response, msg string) error {
+	e := &Error{Backend: backend, StatusCode: resp.StatusCode}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		e.Kind = KindRateLimited
+		e.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	case resp.StatusCode >= 500:
+		e.Kind = KindServer
+	}
+	if msg == "" {
+		msg = fmt.Sprintf("%s returned status %d", backend, resp.StatusCode)
+	}
+	e.Err = errors.New(msg)
+	return e
+}
+
+// Invalid reports a response that arrived but could not be understood, such
+// as an HTML error page where JSON was expected.
+func Invalid(backend string, err error) error {
+	return &Error{Kind: KindInvalidResponse, Backend: backend, Err: err}
+}
+
+// parseRetryAfter accepts both forms allowed by RFC 9110: delay-seconds and
+// an HTTP-date.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// IsNetwork reports whether err means the backend could not be reached in
+// time, as opposed to the backend answering with an error.
+func IsNetwork(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && (e.Kind == KindUnreachable || e.Kind == KindTimeout)
+}
+
+// Explain returns a short, user-facing explanation of what went wrong and
+// what to try next, or "" when err is not a classified backend error.
+func Explain(err error) string {
+	var e *Error
+	if !errors.As(err, &e) {
+		return ""
+	}
+	switch e.Kind {
+	case KindUnreachable:
+		return fmt.Sprintf("Could not reach %s. Check your network connection, VPN or proxy, and that the server is running.", e.Backend)
+	case KindTimeout:
+		return fmt.Sprintf("%s took too long to answer. Large diffs or a cold model can cause this; try again.", e.Backend)
+	case KindRateLimited:
+		if e.RetryAfter > 0 {
+			return fmt.Sprintf("%s is rate limiting requests. Wait about %s before retrying.", e.Backend, e.RetryAfter.Round(time.Second))
+		}
+		return fmt.Sprintf("%s is rate limiting requests. Wait a moment before retrying.", e.Backend)
+	case KindServer:
+		return fmt.Sprintf("%s had an internal problem (status %d). This is usually temporary.", e.Backend, e.StatusCode)
+	case KindInvalidResponse:
+		return fmt.Sprintf("%s sent a response diny could not understand. A proxy or captive portal may be in the way, or the model ignored the expected format.", e.Backend)
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy bounds how often and how long Retry keeps trying.
+type Policy struct {
+	Attempts      int           // total tries, including the first
+	BaseDelay     time.Duration // backoff before the second try
+	MaxDelay      time.Duration // cap for exponential backoff
+	MaxRetryAfter time.Duration // give up when the server asks us to wait longer
+}
+
+var DefaultPolicy = Policy{
+	Attempts:      3,
+	BaseDelay:     500 * time.Millisecond,
+	MaxDelay:      8 * time.Second,
+	MaxRetryAfter: 30 * time.Second,
+}
+
+type permanent struct {
+	err error
+}
+
+func (p permanent) Error() string { return p.err.Error() }
+func (p permanent) Unwrap() error { return p.err }
+
+// Permanent marks err as not worth retrying even if its Kind normally is,
+// e.g. when part of a streamed answer has already been shown.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanent{err: err}
+}
+
+// Retry calls fn until it succeeds, fails with an error that is not
+// Temporary, ctx is done, or the policy's attempts are used up. Backoff is
+// exponential with full jitter; rate-limit responses wait for Retry-After.
+func Retry[T any](ctx context.Context, p Policy, fn func() (T, error)) (T, error) {
+	var zero T
+	for attempt := 1; ; attempt++ {
+		v, err := fn()
+		if err == nil {
+			return v, nil
+		}
+
+		var perm permanent
+		if errors.As(err, &perm) {
+			return zero, perm.err
+		}
+		if ctx.Err() != nil {
+			return zero, ctx.Err()
+		}
+
+		var be *Error
+		if !errors.As(err, &be) || !be.Temporary() || attempt >= p.Attempts {
+			return zero, err
+		}
+
+		delay := p.backoff(attempt)
+		if be.Kind == KindRateLimited && be.RetryAfter > 0 {
+			if be.RetryAfter > p.MaxRetryAfter {
+				return zero, err
+			}
+			delay = be.RetryAfter
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return zero, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+func (p Policy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay << (attempt - 1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d) + 1
+}
@@ -0,0 +1,111 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testPolicy = Policy{
+	Attempts:      3,
+	BaseDelay:     time.Millisecond,
+	MaxDelay:      5 * time.Millisecond,
+	MaxRetryAfter: time.Second,
+}
+
+func TestRetry_RecoversFromServerError(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("<html>Bad Gateway</html>"))
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	got, err := Retry(context.Background(), testPolicy, func() (int, error) {
+		resp, err := http.Get(srv.URL)
+		if err != nil {
+			return 0, Transport("test", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return 0, Status("test", resp, "")
+		}
+		return resp.StatusCode, nil
+	})
+	if err != nil {
+		t.Fatalf("Retry returned error: %v", err)
+	}
+	if got != http.StatusOK || calls != 2 {
+		t.Fatalf("got status %d after %d calls, want 200 after 2", got, calls)
+	}
+}
+
+func TestRetry_StopsOnPermanentErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+	}{
+		{"invalid response", Invalid("test", errors.New("decode response"))},
+		{"unclassified", errors.New("bad request")},
+		{"permanent", Permanent(&Error{Kind: KindServer, Backend: "test"})},
+		{"retry-after too long", &Error{Kind: KindRateLimited, Backend: "test", RetryAfter: time.Hour}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			_, err := Retry(context.Background(), testPolicy, func() (struct{}, error) {
+				calls++
+				return struct{}{}, tc.err
+			})
+			if err == nil || calls != 1 {
+				t.Fatalf("got err=%v after %d calls, want an error after 1", err, calls)
+			}
+		})
+	}
+}
+
+func TestRetry_GivesUpAfterAttempts(t *testing.T) {
+	calls := 0
+	_, err := Retry(context.Background(), testPolicy, func() (struct{}, error) {
+		calls++
+		return struct{}{}, &Error{Kind: KindUnreachable, Backend: "test", Err: errors.New("connection refused")}
+	})
+	var be *Error
+	if !errors.As(err, &be) || be.Kind != KindUnreachable {
+		t.Fatalf("expected unreachable error, got %v", err)
+	}
+	if calls != testPolicy.Attempts {
+		t.Fatalf("got %d calls, want %d", calls, testPolicy.Attempts)
+	}
+}
+
+func TestStatus_RateLimited(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"7"}}}
+	var be *Error
+	if !errors.As(Status("test", resp, ""), &be) {
+		t.Fatal("expected *Error")
+	}
+	if be.Kind != KindRateLimited || be.RetryAfter != 7*time.Second {
+		t.Fatalf("got kind %v retry-after %v", be.Kind, be.RetryAfter)
+	}
+	if Explain(be) == "" {
+		t.Fatal("expected an explanation for rate limiting")
+	}
+}
+
+func TestParseRetryAfter_HTTPDate(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	got := parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now)
+	if got != 90*time.Second {
+		t.Fatalf("got %v, want 90s", got)
+	}
+}
@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/git"
+)
+
+// Store keeps generated responses on disk, one JSON file per key, so an
+// identical request can be answered without contacting the backend.
+type Store struct {
+	dir      string
+	ttl      time.Duration
+	maxBytes int64
+	now      func() time.Time
+}
+
+type entry struct {
+	Created time.Time       `json:"created"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// Open returns the store under <gitdir>/diny/cache, or nil when caching is
+// disabled. A nil *Store is valid and never hits.
+func Open(cfg config.CacheConfig) (*Store, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	gitDir, err := git.FindGitDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find git repository: %w", err)
+	}
+	return New(filepath.Join(gitDir, "diny", "cache"), cfg), nil
+}
+
+// New returns a store rooted at dir.
+func New(dir string, cfg config.CacheConfig) *Store {
+	ttl, _ := time.ParseDuration(cfg.TTL)
+	return &Store{
+		dir:      dir,
+		ttl:      ttl,
+		maxBytes: int64(cfg.MaxSizeMB) << 20,
+		now:      time.Now,
+	}
+}
+
+// Key hashes parts into a cache key. Parts are length-prefixed so that
+// ("ab", "c") and ("a", "bc") never collide.
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		fmt.Fprintf(h, "%d:%s", len(p), p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (s *Store) path(key string) string {
+	return filepath.Join(s.dir, key+".json")
+}
+
+// Get decodes the entry for key into v. It reports false when there is no
+// entry, it has expired or it cannot be decoded.
+func (s *Store) Get(key string, v any) bool {
+	if s == nil {
+		return false
+	}
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return false
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return false
+	}
+	if s.ttl > 0 && s.now().Sub(e.Created) > s.ttl {
+		_ = os.Remove(s.path(key))
+		return false
+	}
+	return json.Unmarshal(e.Value, v) == nil
+}
+
+// Put stores v under key and evicts expired entries, then the oldest ones
+// until the cache fits its size limit.
+func (s *Store) Put(key string, v any) error {
+	if s == nil {
+		return nil
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	data, err := json.Marshal(entry{Created: s.now(), Value: value})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write then rename so a concurrent reader never sees half an entry.
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	s.prune()
+	return nil
+}
+
+func (s *Store) prune() {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	var total int64
+	for _, de := range dirEntries {
+		if de.IsDir() || !strings.HasSuffix(de.Name(), ".json") {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		p := filepath.Join(s.dir, de.Name())
+		if s.ttl > 0 && s.now().Sub(info.ModTime()) > s.ttl {
+			_ = os.Remove(p)
+			continue
+		}
+		files = append(files, file{p, info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+
+	if s.maxBytes <= 0 || total <= s.maxBytes {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= s.maxBytes {
+			break
+		}
+		if os.Remove(f.path) == nil {
+			total -= f.size
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dinoDanic/diny/config"
+)
+
+func TestStore_GetPut(t *testing.T) {
+	s := New(t.TempDir(), config.CacheConfig{Enabled: true, TTL: "1h", MaxSizeMB: 1})
+
+	key := Key("commit", "diff --git a/x b/x")
+	if s.Get(key, new(string)) {
+		t.Fatal("expected a miss on an empty cache")
+	}
+	if err := s.Put(key, "feat: add x"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	var got string
+	if !s.Get(key, &got) || got != "feat: add x" {
+		t.Fatalf("got %q, want cached message", got)
+	}
+}
+
+func TestStore_Expires(t *testing.T) {
+	s := New(t.TempDir(), config.CacheConfig{Enabled: true, TTL: "1h"})
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	key := Key("commit", "diff")
+	if err := s.Put(key, "msg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	s.now = func() time.Time { return now.Add(2 * time.Hour) }
+	if s.Get(key, new(string)) {
+		t.Fatal("expected an expired entry to miss")
+	}
+	if _, err := os.Stat(s.path(key)); !os.IsNotExist(err) {
+		t.Fatal("expected the expired entry to be removed")
+	}
+}
+
+func TestStore_EvictsOldest(t *testing.T) {
+	dir := t.TempDir()
+	s := New(dir, config.CacheConfig{Enabled: true})
+	s.maxBytes = 3000
+
+	big := strings.Repeat("x", 1000)
+	base := time.Now().Add(-time.Minute)
+	for i, k := range []string{"a", "b", "c"} {
+		if err := s.Put(Key(k), big); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		mt := base.Add(time.Duration(i) * time.Second)
+		_ = os.Chtimes(filepath.Join(dir, Key(k)+".json"), mt, mt)
+	}
+
+	if s.Get(Key("a"), new(string)) {
+		t.Fatal("expected the oldest entry to be evicted")
+	}
+	if !s.Get(Key("c"), new(string)) {
+		t.Fatal("expected the newest entry to survive")
+	}
+}
+
+func TestNilStore(t *testing.T) {
+	var s *Store
+	if s.Get(Key("x"), new(string)) {
+		t.Fatal("nil store should never hit")
+	}
+	if err := s.Put(Key("x"), "v"); err != nil {
+		t.Fatalf("nil store Put: %v", err)
+	}
+}
+
+func TestKey_LengthPrefixed(t *testing.T) {
+	if Key("ab", "c") == Key("a", "bc") {
+		t.Fatal("keys of differently split parts must differ")
+	}
+}
@@ -1,6 +1,7 @@
 package changelog
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,6 +13,7 @@ import (
 	"github.com/dinoDanic/diny/config"
 	"github.com/dinoDanic/diny/git"
 	"github.com/dinoDanic/diny/groq"
+	"github.com/dinoDanic/diny/summarize"
 	"github.com/dinoDanic/diny/ui"
 )
 
@@ -98,6 +100,18 @@ func generateChangelog(olderRef, newerRef string, cfg *config.Config) error {
 		return err
 	}
 
+	if summarize.TooLarge(diff, cfg) {
+		err := ui.WithSpinner("Summarising large diff...", func() error {
+			var sumErr error
+			diff, sumErr = summarize.Condense(context.Background(), diff, cfg, nil)
+			return sumErr
+		})
+		if err != nil {
+			ui.Error("Failed to summarise diff: %v", err)
+			return err
+		}
+	}
+
 	repoName := git.GetRepoName()
 	gitName := git.GetGitName()
 
@@ -106,7 +120,7 @@ func generateChangelog(olderRef, newerRef string, cfg *config.Config) error {
 	var result string
 	spinErr := ui.WithSpinner("Generating changelog...", func() error {
 		var genErr error
-		result, genErr = groq.CreateChangelogWithGroq(prompt, cfg)
+		result, genErr = groq.CreateChangelogWithGroq(context.Background(), prompt, cfg)
 		return genErr
 	})
 
@@ -127,11 +141,6 @@ func buildChangelogPrompt(repoName, gitName, olderRef, newerRef string, commits
 		commitLines[i] = "- " + c
 	}
 
-	diffSummary := diff
-	if len(diffSummary) > 4000 {
-		diffSummary = diffSummary[:4000] + "\n... (diff truncated)"
-	}
-
 	return fmt.Sprintf(`Generate a changelog for the following repository changes.
 
 Repository: %s
@@ -156,7 +165,7 @@ Keep it concise and human-readable.`,
 		newerRef,
 		len(commits),
 		strings.Join(commitLines, "\n"),
-		diffSummary,
+		diff,
 	)
 }
 
@@ -213,7 +222,7 @@ func handleGenerateFlow(result, rangeLabel, olderRef, newerRef, prompt string, c
 		var newResult string
 		spinErr := ui.WithSpinner("Regenerating changelog...", func() error {
 			var genErr error
-			newResult, genErr = groq.CreateChangelogWithGroq(modifiedPrompt, cfg)
+			newResult, genErr = groq.CreateChangelogWithGroq(context.Background(), modifiedPrompt, cfg)
 			return genErr
 		})
 		if spinErr != nil {
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/dinoDanic/diny/git"
+	"github.com/dinoDanic/diny/logging"
 	"github.com/spf13/cobra"
 )
 
@@ -44,7 +45,7 @@ func setupGitAlias() {
 
 	aliasScript := fmt.Sprintf(`!%s commit`, dinyPath)
 
-	cmd := exec.Command("git", "config", "--global", "alias.auto", aliasScript)
+	cmd := logging.Command("git", "config", "--global", "alias.auto", aliasScript)
 	err = cmd.Run()
 	if err != nil {
 		fmt.Printf("❌ Failed to set git alias: %v\n", err)
@@ -56,7 +57,7 @@ func setupGitAlias() {
 }
 
 func removeGitAlias() {
-	cmd := exec.Command("git", "config", "--global", "--unset", "alias.auto")
+	cmd := logging.Command("git", "config", "--global", "--unset", "alias.auto")
 	err := cmd.Run()
 	if err != nil {
 		if strings.Contains(err.Error(), "exit status 5") {
@@ -1,8 +1,19 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/dinoDanic/diny/commit"
+	"github.com/dinoDanic/diny/git"
+	"github.com/dinoDanic/diny/logging"
 	"github.com/dinoDanic/diny/prompts"
 	"github.com/dinoDanic/diny/tui/app"
+	"github.com/dinoDanic/diny/ui"
 	"github.com/dinoDanic/diny/update"
 	"github.com/dinoDanic/diny/version"
 	"github.com/spf13/cobra"
@@ -16,17 +27,37 @@ var commitCmd = &cobra.Command{
 Diny reads your staged changes and propose a commit message, and lets
 you commit, edit, regenerate, or refine it—all`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			printDryRun()
+			return
+		}
+		if cont, _ := cmd.Flags().GetBool("continue-split"); cont {
+			continueSplit()
+			return
+		}
+		if abort, _ := cmd.Flags().GetBool("abort-split"); abort {
+			abortSplit()
+			return
+		}
+		if run, err := commit.LoadSplitState(); err == nil && run != nil {
+			ui.Warning("A split stopped at group %d of %d; run diny commit --continue-split or --abort-split", run.Next+1, len(run.Plan))
+		}
+
 		checker := update.NewUpdateChecker(version.Get())
 		updateCh := checker.CheckAsync()
 
 		noVerify, _ := cmd.Flags().GetBool("no-verify")
 		push, _ := cmd.Flags().GetBool("push")
 		print, _ := cmd.Flags().GetBool("print")
+		offline, _ := cmd.Flags().GetBool("offline")
+		amend, _ := cmd.Flags().GetBool("amend")
 
 		result := app.Run(AppConfig, version.Get(), app.Options{
 			NoVerify: noVerify,
 			Push:     push,
 			Print:    print,
+			Offline:  offline,
+			Amend:    amend,
 		})
 
 		if result.CommitSucceeded {
@@ -37,9 +68,92 @@ you commit, edit, regenerate, or refine it—all`,
 	},
 }
 
+// printDryRun prints the request a commit would send for the staged
+// changes, without sending it.
+func printDryRun() {
+	diff, err := git.GetGitDiff()
+	if err != nil {
+		ui.Error("Failed to get git diff: %v", err)
+		os.Exit(1)
+	}
+	if diff == "" {
+		ui.Error("No staged changes to describe")
+		os.Exit(1)
+	}
+	out, err := commit.DryRun("commit", diff, diff, AppConfig, nil)
+	if err != nil {
+		ui.Error("%v", err)
+		os.Exit(1)
+	}
+	fmt.Println(out)
+}
+
+// loadSplit returns the saved split or exits if there is none.
+func loadSplit() *commit.SplitRun {
+	run, err := commit.LoadSplitState()
+	if err != nil {
+		ui.Error("%v", err)
+		os.Exit(1)
+	}
+	if run == nil {
+		ui.Error("No split in progress")
+		os.Exit(1)
+	}
+	return run
+}
+
+// continueSplit commits the groups a stopped split has left, starting with
+// the one that failed, then pushes if the split was started with --push.
+func continueSplit() {
+	run := loadSplit()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	done := len(run.Hashes)
+	err := run.Continue(ctx)
+	for i, hash := range run.Hashes[done:] {
+		ui.Success("[%d/%d] %s %s", done+i+1, len(run.Plan), hash, strings.SplitN(run.Plan[done+i].Message, "\n", 2)[0])
+	}
+	if err != nil {
+		var splitErr *commit.SplitError
+		if errors.As(err, &splitErr) {
+			ui.Error("Group %d of %d failed:\n%s", splitErr.Group+1, len(run.Plan), splitErr.Output)
+			ui.Primary("Fix it and run diny commit --continue-split again, or diny commit --abort-split")
+		} else {
+			ui.Error("%v", err)
+		}
+		os.Exit(1)
+	}
+
+	if run.Push {
+		if out, err := logging.Command("git", "push").CombinedOutput(); err != nil {
+			ui.Error("Committed the split but push failed: %s", strings.TrimSpace(string(out)))
+			os.Exit(1)
+		}
+		commit.MarkPushed(run.Head)
+	}
+	ui.Success("Split finished: %d commits", len(run.Hashes))
+}
+
+// abortSplit undoes the commits of a stopped split and restores the index
+// from before it, leaving the worktree alone.
+func abortSplit() {
+	run := loadSplit()
+	if err := run.Abort(); err != nil {
+		ui.Error("%v", err)
+		os.Exit(1)
+	}
+	ui.Success("Split aborted; HEAD and the index are back as they were")
+}
+
 func init() {
 	commitCmd.Flags().Bool("no-verify", false, "Skip pre-commit and commit-msg hooks on every commit")
 	commitCmd.Flags().Bool("push", false, "Push after committing (after the final commit when splitting)")
 	commitCmd.Flags().Bool("print", false, "Print the generated message to stdout (incompatible with split)")
+	commitCmd.Flags().Bool("dry-run", false, "Print the exact request that would be sent for the staged changes and exit")
+	commitCmd.Flags().Bool("amend", false, "Amend HEAD, refining its message from HEAD's changes plus what is staged")
+	commitCmd.Flags().Bool("continue-split", false, "Resume a split that stopped at a failed group")
+	commitCmd.Flags().Bool("abort-split", false, "Undo a stopped split and restore the index from before it")
+	commitCmd.Flags().Bool("offline", false, "Build the message from staged file names and diffstat without contacting a backend")
 	rootCmd.AddCommand(commitCmd)
 }
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/dinoDanic/diny/commit"
+	"github.com/dinoDanic/diny/logging"
+	"github.com/dinoDanic/diny/ui"
+	"github.com/spf13/cobra"
+)
+
+// hookTimeout bounds how long a commit waits on the backend.
+const hookTimeout = 30 * time.Second
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Fill git commit's message with diny through a prepare-commit-msg hook",
+	Long: `Install a prepare-commit-msg hook so a plain git commit, from the command
+line or an IDE, opens with a generated message.
+
+The hook goes where git looks for hooks, following core.hooksPath. A
+prepare-commit-msg hook that is already there is kept and run first.
+
+The message buffer is left alone for merges, squashes, amends, -m, -F,
+-c/-C and commit templates, when nothing is staged, and when the backend
+cannot be reached; the commit itself always goes ahead.
+
+Examples:
+  diny hook install     # Install the hook in this repository
+  diny hook status      # Show whether it is installed
+  diny hook uninstall   # Remove it, restoring any hook it replaced`,
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the prepare-commit-msg hook",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dinyPath, err := getDinyPath()
+		if err != nil {
+			ui.Error("Error finding diny executable: %v", err)
+			os.Exit(1)
+		}
+		s, err := commit.InstallHook(dinyPath)
+		if err != nil {
+			ui.Error("%v", err)
+			os.Exit(1)
+		}
+		ui.Success("Installed the prepare-commit-msg hook at %s", s.Path)
+		if s.Chained != "" {
+			ui.Primary("The existing hook runs first, from %s", s.Chained)
+		}
+	},
+}
+
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the prepare-commit-msg hook",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := commit.UninstallHook()
+		if err != nil {
+			ui.Error("%v", err)
+			os.Exit(1)
+		}
+		ui.Success("Removed the prepare-commit-msg hook from %s", s.Path)
+		if s.Chained != "" {
+			ui.Primary("Restored the hook it ran first")
+		}
+	},
+}
+
+var hookStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the prepare-commit-msg hook is installed",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := commit.GetHookStatus()
+		if err != nil {
+			ui.Error("%v", err)
+			os.Exit(1)
+		}
+		switch {
+		case s.Installed:
+			ui.Success("Installed at %s", s.Path)
+		case s.Foreign:
+			ui.Warning("Not installed; another hook is at %s and would be chained", s.Path)
+		default:
+			ui.Primary("Not installed; diny hook install puts it at %s", s.Path)
+		}
+		if s.Installed && s.Chained != "" {
+			ui.Primary("Runs %s first", s.Chained)
+		}
+	},
+}
+
+// hookRunCmd is what the installed hook calls, with git's arguments:
+// the message file, then the message source and commit if any.
+var hookRunCmd = &cobra.Command{
+	Use:    "run <message-file> [source] [commit]",
+	Hidden: true,
+	Args:   cobra.RangeArgs(1, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		source := ""
+		if len(args) > 1 {
+			source = args[1]
+		}
+		if commit.HookSkips(source) || AppConfig == nil {
+			return
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+		defer cancel()
+
+		fmt.Fprintln(os.Stderr, "diny: generating commit message...")
+		if _, err := commit.PrepareCommitMessage(ctx, args[0], AppConfig); err != nil {
+			logging.Warn("hook skipped", "error", err.Error())
+			fmt.Fprintf(os.Stderr, "diny: skipped, %v\n", err)
+		}
+	},
+}
+
+func init() {
+	hookCmd.AddCommand(hookInstallCmd, hookUninstallCmd, hookStatusCmd, hookRunCmd)
+	rootCmd.AddCommand(hookCmd)
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/feedback"
+	"github.com/dinoDanic/diny/groq"
+	"github.com/dinoDanic/diny/privacy"
+	"github.com/dinoDanic/diny/server"
+	"github.com/dinoDanic/diny/ui"
+	"github.com/dinoDanic/diny/version"
+	"github.com/spf13/cobra"
+)
+
+var privacyCmd = &cobra.Command{
+	Use:   "privacy",
+	Short: "Show exactly what diny sends under the current config",
+	Long: `Print the fields diny transmits when generating messages and when
+sending feedback, with the privacy settings and environment applied.
+
+Identity fields are controlled by the privacy block in your config:
+
+  privacy:
+    name: send       # send, hash or omit
+    email: hash
+    repo_name: omit
+    system: send
+
+Set DO_NOT_TRACK=1 or DINY_NO_TELEMETRY=1 to disable feedback prompts and
+feedback uploads entirely.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ui.Box("Generation requests", describeRequests(AppConfig))
+		ui.Box("Feedback", describeFeedback(AppConfig))
+	},
+}
+
+func describeRequests(cfg *config.Config) string {
+	var endpoint, model string
+	switch cfg.Provider.Name {
+	case config.ProviderOllama:
+		endpoint, model = cfg.Provider.Ollama.Endpoint, cfg.Provider.Ollama.Model
+	case config.ProviderOpenAI:
+		endpoint, model = cfg.Provider.OpenAI.BaseURL, cfg.Provider.OpenAI.Model
+	}
+	if endpoint != "" {
+		return fmt.Sprintf("Sent to %s (model %s).\n\nOnly the system prompt built from your commit settings and the staged diff,\nwith secrets redacted. No identity fields are sent and the diny service\nis not contacted.", endpoint, model)
+	}
+
+	id := privacy.Collect(cfg.Privacy)
+	req := groq.Request{
+		Type:       "commit",
+		UserPrompt: "<staged diff, secrets redacted>",
+		Version:    version.Get(),
+		Name:       id.Name,
+		Email:      id.Email,
+		RepoName:   id.RepoName,
+		Config:     cfg,
+		System:     id.System,
+	}
+	body, err := prettyJSON(req)
+	if err != nil {
+		return fmt.Sprintf("failed to render request: %v", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "POST %s/api/requests\n\n", server.ServerConfig.BaseURL)
+	b.WriteString(body)
+	b.WriteString("\n\ntype is commit, split or timeline. Regenerating a split plan also sends\npreviousPlans and your feedback text.")
+	if n := omitted(id); n != "" {
+		fmt.Fprintf(&b, "\nNot sent: %s.", n)
+	}
+	return b.String()
+}
+
+func describeFeedback(cfg *config.Config) string {
+	if off, env := privacy.TelemetryDisabled(); off {
+		return fmt.Sprintf("Nothing is sent: %s is set.", env)
+	}
+	if !cfg.Prompts.Enabled {
+		return "Nothing is sent: prompts.enabled is false."
+	}
+
+	body, err := prettyJSON(feedback.NewPayload("rating", "<your answer>", cfg))
+	if err != nil {
+		return fmt.Sprintf("failed to render payload: %v", err)
+	}
+	return fmt.Sprintf("Only after you answer a rating, star or feedback prompt.\n\nPOST %s/api/feedback\n\n%s", server.ServerConfig.BaseURL, body)
+}
+
+// prettyJSON indents v without escaping the <placeholders>.
+func prettyJSON(v any) (string, error) {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+func omitted(id privacy.Identity) string {
+	var names []string
+	if id.Name == "" {
+		names = append(names, "name")
+	}
+	if id.Email == "" {
+		names = append(names, "email")
+	}
+	if id.RepoName == "" {
+		names = append(names, "repoName")
+	}
+	if id.System == "" {
+		names = append(names, "system")
+	}
+	return strings.Join(names, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(privacyCmd)
+}
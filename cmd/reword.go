@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/dinoDanic/diny/commit"
+	"github.com/dinoDanic/diny/tui/reword"
+	"github.com/dinoDanic/diny/ui"
+	"github.com/dinoDanic/diny/version"
+	"github.com/spf13/cobra"
+)
+
+var rewordCmd = &cobra.Command{
+	Use:   "reword <range>",
+	Short: "Rewrite the messages of existing commits",
+	Long: `Generate a new message for every commit in a range from that commit's
+own diff, review them in a list where each can be edited, regenerated or
+kept, then rewrite the branch with an automated rebase.
+
+The range is anything git log takes, e.g. main..HEAD; a single commit
+means from there to HEAD. Uncommitted changes are stashed and put back.
+
+Merge commits and commits already on a remote branch are refused unless
+--force is given; with --force merges are kept as they are.
+
+Examples:
+  diny reword main..HEAD
+  diny reword HEAD~5`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		force, _ := cmd.Flags().GetBool("force")
+		plan, err := commit.PlanReword(args[0], force)
+		if err != nil {
+			ui.Error("%v", err)
+			os.Exit(1)
+		}
+		reword.Run(AppConfig, version.Get(), plan)
+	},
+}
+
+func init() {
+	rewordCmd.Flags().Bool("force", false, "Reword even through merge commits or commits that were already pushed")
+	rootCmd.AddCommand(rewordCmd)
+}
@@ -6,8 +6,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/git"
+	"github.com/dinoDanic/diny/logging"
+	"github.com/dinoDanic/diny/redact"
+	"github.com/dinoDanic/diny/server"
 	"github.com/dinoDanic/diny/ui"
 	"github.com/dinoDanic/diny/version"
 	"github.com/spf13/cobra"
@@ -28,6 +33,19 @@ spending time manually writing messages.
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		fmt.Println()
 
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		logFile, _ := cmd.Flags().GetString("log-file")
+		if err := logging.Setup(logging.Options{Verbose: verbose, File: logFile}); err != nil && logFile != "" {
+			ui.Warning("Logging disabled: %v", err)
+		}
+
+		if dir, _ := cmd.Flags().GetString("dir"); dir != "" {
+			if err := os.Chdir(dir); err != nil {
+				ui.Error("Cannot change to %s: %v", dir, err)
+				os.Exit(1)
+			}
+		}
+
 		if cmd.Name() == "theme" {
 			return
 		}
@@ -47,12 +65,29 @@ spending time manually writing messages.
 
 		AppConfig = result.Config
 
+		if AppConfig != nil {
+			server.Configure(AppConfig.Server.URL)
+			git.ConfigureDiff(AppConfig.Diff.Exclude, AppConfig.Diff.Include)
+			installLogScrubber(AppConfig.Redact)
+		}
+		logging.Info("start", "version", version.Get(), "command", cmd.CommandPath(), "args", logging.Scrub(strings.Join(os.Args[1:], " ")))
+
 		if AppConfig != nil && AppConfig.Theme != "" {
 			ui.SetTheme(AppConfig.Theme)
 		}
 	},
 }
 
+// installLogScrubber redacts secrets from everything logged. It applies
+// even when redact.enabled is off for requests: the log is a file on disk.
+func installLogScrubber(cfg config.RedactConfig) {
+	cfg.Enabled = true
+	logging.Scrub = func(text string) string {
+		out, _ := redact.Apply(text, cfg)
+		return out
+	}
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -66,6 +101,10 @@ func init() {
 	// Set version for the root command
 	rootCmd.Version = version.Get()
 
+	rootCmd.PersistentFlags().Bool("verbose", false, "Log at debug level, including prompts and responses (secrets still redacted)")
+	rootCmd.PersistentFlags().StringP("dir", "C", "", "Run as if diny was started in this directory, like git -C")
+	rootCmd.PersistentFlags().String("log-file", "", "Write the log here instead of ~/.config/diny/logs/diny.log")
+
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/serve"
+	"github.com/dinoDanic/diny/ui"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Host the diny API for your team using a local model backend",
+	Long: `Run a server that speaks the same /api/requests and /api/feedback protocol
+as the hosted diny service and forwards generation to the backend configured
+under provider (ollama or openai).
+
+On the server machine:
+  diny serve --addr :3578
+
+On every client, keep provider.name: diny and point it at the server:
+  export DINY_SERVER_URL=http://team-box:3578
+or set server.url in the config.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		addr, _ := cmd.Flags().GetString("addr")
+		feedbackPath, _ := cmd.Flags().GetString("feedback-file")
+
+		var feedbackOut io.Writer
+		if feedbackPath != "" {
+			f, err := os.OpenFile(feedbackPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				ui.Error("Failed to open feedback file: %v", err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			feedbackOut = f
+		}
+
+		handler, err := serve.Handler(serve.Options{Config: AppConfig, Feedback: feedbackOut})
+		if err != nil {
+			ui.Error("%v", err)
+			os.Exit(1)
+		}
+
+		model := AppConfig.Provider.Ollama.Model
+		if AppConfig.Provider.Name == config.ProviderOpenAI {
+			model = AppConfig.Provider.OpenAI.Model
+		}
+		ui.Box("diny serve", "Listening on "+addr+"\nForwarding to "+string(AppConfig.Provider.Name)+" ("+model+")\n\nPoint clients at it with DINY_SERVER_URL=http://<this-host>"+addr)
+
+		srv := &http.Server{
+			Addr:              addr,
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(shutdownCtx)
+		}()
+
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ui.Error("Server failed: %v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	serveCmd.Flags().String("addr", ":3578", "Address to listen on")
+	serveCmd.Flags().String("feedback-file", "", "Append feedback received from clients to this file as JSON lines")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/dinoDanic/diny/commit"
+	"github.com/dinoDanic/diny/ui"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo the last commit, amend, split or reword diny made",
+	Long: `Undo the last commit, amend, split or reword diny made in this repository.
+
+HEAD is soft reset to where it was before and the index is restored as it
+was staged, so nothing in the worktree changes and the changes are ready
+to commit again. Run it repeatedly to step further back.
+
+diny refuses when HEAD has moved since, or when the commits were already
+pushed; use git revert for published history.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		entry, err := commit.Undo()
+		if err != nil {
+			ui.Error("%v", err)
+			os.Exit(1)
+		}
+		ui.Success("Undid the last %s (%d commit(s)); HEAD is back at %s and the changes are staged", entry.Kind, len(entry.Subjects), entry.Base[:min(7, len(entry.Base))])
+		for _, s := range entry.Subjects {
+			ui.Primary("  %s", s)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+}
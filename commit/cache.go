@@ -0,0 +1,65 @@
+package commit
+
+import (
+	"strconv"
+
+	"github.com/dinoDanic/diny/cache"
+	"github.com/dinoDanic/diny/config"
+)
+
+// cacheKey identifies a response by everything that shapes it: the request
+// kind, the filtered diff, the message settings and the model answering.
+// Settings that only affect what happens after generation (theme, hash
+// copying) are left out so changing them does not invalidate the cache.
+func cacheKey(kind, diff string, cfg *config.Config) string {
+	c := cfg.Commit
+	return cache.Key(
+		kind,
+		diff,
+		strconv.FormatBool(c.Conventional),
+		strconv.FormatBool(c.Emoji),
+		string(c.Tone),
+		string(c.Length),
+		c.CustomInstructions,
+		cfg.Provider.Identity(),
+	)
+}
+
+func openCache(cfg *config.Config) *cache.Store {
+	store, err := cache.Open(cfg.Cache)
+	if err != nil {
+		return nil
+	}
+	return store
+}
+
+// CachedCommitMessage returns the message last generated for this diff and
+// configuration, if it is still in the cache.
+func CachedCommitMessage(gitDiff string, cfg *config.Config) (string, bool) {
+	var msg string
+	if !openCache(cfg).Get(cacheKey("commit", gitDiff, cfg), &msg) || msg == "" {
+		return "", false
+	}
+	return msg, true
+}
+
+// CacheCommitMessage records msg as the answer for this diff and
+// configuration. Failures are ignored; the cache is only an optimisation.
+func CacheCommitMessage(gitDiff string, cfg *config.Config, msg string) {
+	_ = openCache(cfg).Put(cacheKey("commit", gitDiff, cfg), msg)
+}
+
+// CachedSplitPlan returns the first plan generated for this diff and
+// configuration, if it is still in the cache.
+func CachedSplitPlan(gitDiff string, cfg *config.Config) ([]SplitGroup, bool) {
+	var plan []SplitGroup
+	if !openCache(cfg).Get(cacheKey("split", gitDiff, cfg), &plan) || len(plan) == 0 {
+		return nil, false
+	}
+	return plan, true
+}
+
+// CacheSplitPlan records plan as the answer for this diff and configuration.
+func CacheSplitPlan(gitDiff string, cfg *config.Config, plan []SplitGroup) {
+	_ = openCache(cfg).Put(cacheKey("split", gitDiff, cfg), plan)
+}
@@ -1,12 +1,16 @@
 package commit
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
 	"github.com/dinoDanic/diny/config"
 	"github.com/dinoDanic/diny/groq"
 )
 
-func CreateCommitMessage(gitDiff string, cfg *config.Config) (string, error) {
-	commitMessage, err := groq.CreateCommitMessageWithGroq(gitDiff, cfg)
+func CreateCommitMessage(ctx context.Context, gitDiff string, cfg *config.Config) (string, error) {
+	commitMessage, err := groq.CreateCommitMessageWithGroq(ctx, gitDiff, cfg)
 
 	if err != nil {
 		return "", err
@@ -14,3 +18,36 @@ func CreateCommitMessage(gitDiff string, cfg *config.Config) (string, error) {
 
 	return commitMessage, nil
 }
+
+// CreateCommitMessageStream is CreateCommitMessage with incremental output:
+// onChunk is called with each piece of text as the backend produces it.
+func CreateCommitMessageStream(ctx context.Context, gitDiff string, cfg *config.Config, onChunk func(string)) (string, error) {
+	return groq.CreateCommitMessageStreamWithGroq(ctx, gitDiff, cfg, onChunk)
+}
+
+// RegeneratePrompt asks for a message unlike the rejected ones.
+func RegeneratePrompt(gitDiff string, rejected []string) string {
+	if len(rejected) == 0 {
+		return gitDiff
+	}
+	var b strings.Builder
+	b.WriteString(gitDiff)
+	b.WriteString("\n\nPrevious commit messages that were not satisfactory:\n")
+	for i, msg := range rejected {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, msg)
+	}
+	b.WriteString("\nPlease generate a different commit message that avoids the style and approach of the previous ones.")
+	return b.String()
+}
+
+// FeedbackPrompt asks for a new message addressing the user's feedback on
+// the current one.
+func FeedbackPrompt(gitDiff, current, feedback string) string {
+	return gitDiff + fmt.Sprintf("\n\nCurrent commit message:\n%s\n\nUser feedback: %s\n\nPlease generate a new commit message that addresses the user's feedback.", current, feedback)
+}
+
+// AmendPrompt asks for HEAD's message refined to describe gitDiff, the
+// whole of the amended commit.
+func AmendPrompt(gitDiff, headMessage string) string {
+	return gitDiff + fmt.Sprintf("\n\nThis diff is the whole of the last commit after amending it. Its current message is:\n%s\n\nPlease refine that message so it describes the whole change: keep what is still accurate, and its wording where it fits, and cover what the amended changes add or alter.", headMessage)
+}
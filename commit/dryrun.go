@@ -0,0 +1,61 @@
+package commit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/git"
+	"github.com/dinoDanic/diny/groq"
+	"github.com/dinoDanic/diny/redact"
+	"github.com/dinoDanic/diny/summarize"
+)
+
+// DryRun describes what generating reqType from prompt would send, without
+// sending anything: the size of diff, the staged files whose contents it
+// leaves out, the secrets that would be redacted and the exact request.
+// prompt is diff with any regenerate or feedback instructions appended. A
+// diff over the large_diff budget is shown with its summaries in place, or a
+// placeholder when they have not been generated yet.
+func DryRun(reqType, diff, prompt string, cfg *config.Config, extras *SplitRequestExtras) (string, error) {
+	excluded, err := git.GetExcludedFiles()
+	if err != nil {
+		return "", fmt.Errorf("failed to list excluded files: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Diff: %d characters, %d file(s)\n", len(diff), strings.Count(diff, "diff --git "))
+
+	if len(excluded) == 0 {
+		b.WriteString("Excluded: none\n")
+	} else {
+		names := make([]string, len(excluded))
+		for i, f := range excluded {
+			names[i] = fmt.Sprintf("%s (%s, %s)", f.Path, f.Status, f.Reason)
+		}
+		fmt.Fprintf(&b, "Excluded: %s\n", strings.Join(names, ", "))
+	}
+
+	if summarize.TooLarge(diff, cfg) && strings.HasPrefix(prompt, diff) {
+		suffix := prompt[len(diff):]
+		if digest, ok := summarize.Cached(diff, cfg); ok {
+			prompt = digest + suffix
+			fmt.Fprintf(&b, "Large diff: over the %d character budget, summarised before this request\n", cfg.LargeDiff.Budget)
+		} else {
+			n := summarize.Parts(diff, cfg)
+			prompt = fmt.Sprintf("<summaries of %d part(s) of the diff>", n) + suffix
+			fmt.Fprintf(&b, "Large diff: over the %d character budget, %d summary request(s) would be sent first\n", cfg.LargeDiff.Budget, n)
+		}
+	}
+
+	if _, report := redact.Apply(prompt, cfg.Redact); report.Total() > 0 {
+		fmt.Fprintf(&b, "Redacted: %d secret(s) in %d file(s)\n", report.Total(), len(report))
+	}
+
+	req, err := groq.DryRun(reqType, prompt, cfg, extras)
+	if err != nil {
+		return "", fmt.Errorf("failed to render request: %w", err)
+	}
+	fmt.Fprintf(&b, "\nRequest (%s):\n%s", reqType, req)
+	return b.String(), nil
+}
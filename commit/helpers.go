@@ -3,22 +3,29 @@ package commit
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/atotto/clipboard"
 	"github.com/dinoDanic/diny/config"
 	"github.com/dinoDanic/diny/git"
+	"github.com/dinoDanic/diny/logging"
 )
 
-// TryCommit runs git commit and returns the short hash on success.
+// TryCommit runs git commit, with the commit.trailers from cfg, and returns
+// the short hash on success.
 func TryCommit(message string, push bool, noVerify bool, cfg *config.Config) (string, error) {
-	var commitCmd *exec.Cmd
+	message, err := WithTrailers(message, ConfigTrailers(cfg))
+	if err != nil {
+		return "", err
+	}
+
+	journal := BeginJournal("commit")
+	var commitCmd *logging.Cmd
 	if noVerify {
-		commitCmd = exec.Command("git", "commit", "--no-verify", "-m", message)
+		commitCmd = logging.Command("git", "commit", "--no-verify", "-m", message)
 	} else {
-		commitCmd = exec.Command("git", "commit", "-m", message)
+		commitCmd = logging.Command("git", "commit", "-m", message)
 	}
 	output, err := commitCmd.CombinedOutput()
 	if err != nil {
@@ -27,7 +34,7 @@ func TryCommit(message string, push bool, noVerify bool, cfg *config.Config) (st
 
 	var hash string
 	if cfg != nil && cfg.Commit.HashAfterCommit {
-		hashCmd := exec.Command("git", "rev-parse", "--short", "HEAD")
+		hashCmd := logging.Command("git", "rev-parse", "--short", "HEAD")
 		hashOutput, hashErr := hashCmd.Output()
 		if hashErr == nil {
 			hash = strings.TrimSpace(string(hashOutput))
@@ -36,12 +43,14 @@ func TryCommit(message string, push bool, noVerify bool, cfg *config.Config) (st
 	}
 
 	if push {
-		pushCmd := exec.Command("git", "push")
+		pushCmd := logging.Command("git", "push")
 		pushOut, pushErr := pushCmd.CombinedOutput()
 		if pushErr != nil {
+			journal.Record([]string{message}, false)
 			return hash, fmt.Errorf("committed but push failed: %s", strings.TrimSpace(string(pushOut)))
 		}
 	}
+	journal.Record([]string{message}, push)
 
 	return hash, nil
 }
@@ -0,0 +1,168 @@
+package commit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/git"
+	"github.com/dinoDanic/diny/summarize"
+)
+
+// diny's prepare-commit-msg hook fills the message buffer of a plain
+// `git commit`, so commits made from the command line or an IDE get a
+// generated message too. A hook that was there before is kept next to it
+// and run first.
+
+const (
+	hookName   = "prepare-commit-msg"
+	hookMarker = "# installed by diny hook install"
+	// chainedHookSuffix is appended to the name of a hook diny replaced.
+	chainedHookSuffix = ".diny-chained"
+)
+
+// HookStatus describes the prepare-commit-msg hook of the repository.
+type HookStatus struct {
+	Path      string // where git looks for the hook
+	Installed bool   // the hook is diny's
+	Foreign   bool   // some other hook is there instead
+	Chained   string // the hook diny runs first, if any
+}
+
+// GetHookStatus reports on the prepare-commit-msg hook in the hooks
+// directory git uses, which follows core.hooksPath.
+func GetHookStatus() (*HookStatus, error) {
+	dir, err := git.GetHooksDir()
+	if err != nil {
+		return nil, err
+	}
+	s := &HookStatus{Path: filepath.Join(dir, hookName)}
+	data, err := os.ReadFile(s.Path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read %s: %w", s.Path, err)
+	case strings.Contains(string(data), hookMarker):
+		s.Installed = true
+	default:
+		s.Foreign = true
+	}
+	if _, err := os.Stat(s.Path + chainedHookSuffix); err == nil {
+		s.Chained = s.Path + chainedHookSuffix
+	}
+	return s, nil
+}
+
+// InstallHook installs the hook to run dinyPath. A hook that is already
+// there is moved aside and chained; reinstalling only updates the path.
+func InstallHook(dinyPath string) (*HookStatus, error) {
+	s, err := GetHookStatus()
+	if err != nil {
+		return nil, err
+	}
+	if s.Foreign {
+		if s.Chained != "" {
+			return nil, fmt.Errorf("both %s and %s exist; remove one first", s.Path, s.Chained)
+		}
+		if err := os.Rename(s.Path, s.Path+chainedHookSuffix); err != nil {
+			return nil, fmt.Errorf("failed to move the existing hook aside: %w", err)
+		}
+		s.Chained = s.Path + chainedHookSuffix
+		s.Foreign = false
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if err := os.WriteFile(s.Path, []byte(hookScript(dinyPath)), 0755); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", s.Path, err)
+	}
+	s.Installed = true
+	return s, nil
+}
+
+// UninstallHook removes diny's hook and puts back the one it chained.
+func UninstallHook() (*HookStatus, error) {
+	s, err := GetHookStatus()
+	if err != nil {
+		return nil, err
+	}
+	if !s.Installed {
+		return nil, fmt.Errorf("no diny hook installed at %s", s.Path)
+	}
+	if err := os.Remove(s.Path); err != nil {
+		return nil, fmt.Errorf("failed to remove %s: %w", s.Path, err)
+	}
+	s.Installed = false
+	if s.Chained != "" {
+		if err := os.Rename(s.Chained, s.Path); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", s.Chained, err)
+		}
+	}
+	return s, nil
+}
+
+// hookScript runs the chained hook, failing the commit if it does, then
+// diny. diny never fails the commit, and a missing binary is skipped.
+func hookScript(dinyPath string) string {
+	diny := "'" + strings.ReplaceAll(filepath.ToSlash(dinyPath), "'", `'\''`) + "'"
+	return `#!/bin/sh
+` + hookMarker + `; remove with diny hook uninstall
+chained="$0` + chainedHookSuffix + `"
+if [ -x "$chained" ]; then
+	"$chained" "$@" || exit $?
+fi
+if [ -x ` + diny + ` ]; then
+	` + diny + ` hook run "$@" || true
+fi
+`
+}
+
+// HookSkips reports whether the hook leaves the buffer alone for a commit
+// whose message source git passes as source: merges, squashes, amends and
+// -c/-C, -m and -F, and templates all come with a message already.
+func HookSkips(source string) bool {
+	return source != ""
+}
+
+// PrepareCommitMessage is the hook's work: it generates a message for the
+// staged changes and writes it above what git put in file. It returns
+// false, leaving file alone, when there is nothing staged; any error,
+// such as an unreachable backend, leaves file alone too.
+func PrepareCommitMessage(ctx context.Context, file string, cfg *config.Config) (bool, error) {
+	diff, err := git.GetGitDiff()
+	if err != nil {
+		return false, fmt.Errorf("failed to get git diff: %w", err)
+	}
+	if diff == "" {
+		return false, nil
+	}
+
+	msg, ok := CachedCommitMessage(diff, cfg)
+	if !ok {
+		prompt, err := summarize.Condense(ctx, diff, cfg, nil)
+		if err != nil {
+			return false, fmt.Errorf("failed to summarise large diff: %w", err)
+		}
+		if msg, err = CreateCommitMessage(ctx, prompt, cfg); err != nil {
+			return false, fmt.Errorf("failed to generate commit message: %w", err)
+		}
+		CacheCommitMessage(diff, cfg, msg)
+	}
+	msg = ApplyIssue(msg, cfg.Commit.Issue, BranchIssueKey(cfg))
+	if msg, err = WithTrailers(msg, ConfigTrailers(cfg)); err != nil {
+		return false, err
+	}
+
+	existing, err := os.ReadFile(file)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if err := os.WriteFile(file, []byte(msg+"\n"+string(existing)), 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,132 @@
+package commit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dinoDanic/diny/config"
+)
+
+func TestInstallHook(t *testing.T) {
+	r := newTestRepo(t)
+	r.run("config", "core.hooksPath", ".githooks")
+	hook := filepath.Join(r.dir, ".githooks", "prepare-commit-msg")
+	if err := os.MkdirAll(filepath.Dir(hook), 0755); err != nil {
+		t.Fatal(err)
+	}
+	existing := "#!/bin/sh\necho mine\n"
+	if err := os.WriteFile(hook, []byte(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := InstallHook("/opt/it's/diny")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Path != hook || s.Chained != hook+chainedHookSuffix {
+		t.Errorf("InstallHook = %+v, want the hook at %s chaining the existing one", s, hook)
+	}
+	data, _ := os.ReadFile(hook)
+	if !strings.Contains(string(data), `'/opt/it'\''s/diny' hook run "$@"`) {
+		t.Errorf("hook script does not run diny:\n%s", data)
+	}
+	if _, err := InstallHook("/usr/bin/diny"); err != nil {
+		t.Fatalf("reinstalling: %v", err)
+	}
+	if s, _ := GetHookStatus(); !s.Installed || s.Chained == "" {
+		t.Errorf("GetHookStatus = %+v after reinstalling", s)
+	}
+
+	if _, err := UninstallHook(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(hook); string(data) != existing {
+		t.Errorf("uninstall left %q, want the original hook back", data)
+	}
+	if _, err := UninstallHook(); err == nil {
+		t.Errorf("uninstalling a hook diny did not install should fail")
+	}
+}
+
+func TestHookSkips(t *testing.T) {
+	tests := []struct {
+		source string
+		skip   bool
+	}{
+		{"", false},
+		{"merge", true},
+		{"squash", true},
+		{"commit", true},
+		{"message", true},
+		{"template", true},
+	}
+	for _, tt := range tests {
+		if got := HookSkips(tt.source); got != tt.skip {
+			t.Errorf("HookSkips(%q) = %v, want %v", tt.source, got, tt.skip)
+		}
+	}
+}
+
+func TestPrepareCommitMessage(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("a.txt", "a\n")
+	r.run("add", "a.txt")
+	t.Setenv("DINY_TEST_KEY", "sk-test")
+
+	var fail atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if fail.Load() {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]string{"role": "assistant", "content": "feat: add a"}},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		Theme:  "catppuccin",
+		Commit: config.CommitConfig{Tone: config.Casual, Length: config.Short},
+		Provider: config.ProviderConfig{
+			Name: config.ProviderOpenAI,
+			OpenAI: config.OpenAIConfig{
+				BaseURL:   srv.URL + "/v1",
+				Model:     "test-model",
+				APIKeyEnv: "DINY_TEST_KEY",
+			},
+		},
+	}
+	buffer := "\n# Please enter the commit message for your changes.\n"
+	file := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+
+	if err := os.WriteFile(file, []byte(buffer), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := PrepareCommitMessage(context.Background(), file, cfg); !ok || err != nil {
+		t.Fatalf("PrepareCommitMessage = %v, %v", ok, err)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "feat: add a\n"+buffer {
+		t.Errorf("buffer = %q, want the message above git's", data)
+	}
+
+	fail.Store(true)
+	if err := os.WriteFile(file, []byte(buffer), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := PrepareCommitMessage(context.Background(), file, cfg); ok || err == nil {
+		t.Fatalf("PrepareCommitMessage with the backend down = %v, %v", ok, err)
+	}
+	if data, _ := os.ReadFile(file); string(data) != buffer {
+		t.Errorf("buffer = %q, want it untouched", data)
+	}
+}
@@ -0,0 +1,79 @@
+package commit
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/git"
+)
+
+// IssueKey finds the commit.issue key in branch: the first capture group
+// of the pattern, or the whole match without one. It is "" when the
+// pattern is unset or does not match.
+func IssueKey(issue config.IssueConfig, branch string) string {
+	if issue.Pattern == "" {
+		return ""
+	}
+	re, err := regexp.Compile(issue.Pattern)
+	if err != nil {
+		return ""
+	}
+	m := re.FindStringSubmatch(branch)
+	switch {
+	case m == nil:
+		return ""
+	case len(m) > 1:
+		return m[1]
+	default:
+		return m[0]
+	}
+}
+
+// BranchIssueKey is IssueKey for the current branch.
+func BranchIssueKey(cfg *config.Config) string {
+	if cfg == nil || cfg.Commit.Issue.Pattern == "" {
+		return ""
+	}
+	branch, err := git.GetCurrentBranch()
+	if err != nil {
+		return ""
+	}
+	return IssueKey(cfg.Commit.Issue, branch)
+}
+
+// IssueTrailer is the trailer for key when commit.issue places it in a
+// trailer, or "" otherwise.
+func IssueTrailer(issue config.IssueConfig, key string) string {
+	if key == "" || issue.Placement != config.IssueTrailer {
+		return ""
+	}
+	return "Refs: " + key
+}
+
+// conventionalSubject matches "type(scope)!: " after an optional emoji.
+var conventionalSubject = regexp.MustCompile(`^((?:[^\w\s]+\s+)?[a-z]+)(\([^)]*\))?(!?): `)
+
+// ApplyIssue puts key into message's subject for the prefix and scope
+// placements. The scope placement replaces any scope the message has and
+// falls back to a prefix when the subject is not conventional. A subject
+// that already mentions key is left alone, as is every message for the
+// trailer placement, which goes through IssueTrailer instead.
+func ApplyIssue(message string, issue config.IssueConfig, key string) string {
+	if key == "" || issue.Placement == config.IssueTrailer {
+		return message
+	}
+	subject, rest, multiline := strings.Cut(message, "\n")
+	if strings.Contains(subject, key) {
+		return message
+	}
+	if m := conventionalSubject.FindStringSubmatchIndex(subject); m != nil && issue.Placement == config.IssueScope {
+		subject = subject[:m[3]] + "(" + key + ")" + subject[m[6]:]
+	} else {
+		subject = key + " " + subject
+	}
+	if !multiline {
+		return subject
+	}
+	return subject + "\n" + rest
+}
@@ -0,0 +1,50 @@
+package commit
+
+import (
+	"testing"
+
+	"github.com/dinoDanic/diny/config"
+)
+
+func TestIssueKey(t *testing.T) {
+	tests := []struct {
+		pattern, branch, want string
+	}{
+		{`([A-Z]+-\d+)`, "feature/ABC-123-login", "ABC-123"},
+		{`[A-Z]+-\d+`, "ABC-7", "ABC-7"},
+		{`#(\d+)`, "fix/#42", "42"},
+		{`([A-Z]+-\d+)`, "main", ""},
+		{"", "feature/ABC-123", ""},
+	}
+	for _, tt := range tests {
+		if got := IssueKey(config.IssueConfig{Pattern: tt.pattern}, tt.branch); got != tt.want {
+			t.Errorf("IssueKey(%q, %q) = %q, want %q", tt.pattern, tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestApplyIssue(t *testing.T) {
+	tests := []struct {
+		placement config.IssuePlacement
+		message   string
+		want      string
+	}{
+		{config.IssuePrefix, "feat: add login", "ABC-1 feat: add login"},
+		{"", "Add login\n\nbody", "ABC-1 Add login\n\nbody"},
+		{config.IssueScope, "feat: add login", "feat(ABC-1): add login"},
+		{config.IssueScope, "fix(api)!: drop v1\n\nbody", "fix(ABC-1)!: drop v1\n\nbody"},
+		{config.IssueScope, "✨ feat: add login", "✨ feat(ABC-1): add login"},
+		{config.IssueScope, "Add login", "ABC-1 Add login"},
+		{config.IssuePrefix, "feat(ABC-1): add login", "feat(ABC-1): add login"},
+		{config.IssueTrailer, "feat: add login", "feat: add login"},
+	}
+	for _, tt := range tests {
+		got := ApplyIssue(tt.message, config.IssueConfig{Placement: tt.placement}, "ABC-1")
+		if got != tt.want {
+			t.Errorf("ApplyIssue(%q, %s) = %q, want %q", tt.message, tt.placement, got, tt.want)
+		}
+	}
+	if got := IssueTrailer(config.IssueConfig{Placement: config.IssueTrailer}, "ABC-1"); got != "Refs: ABC-1" {
+		t.Errorf("IssueTrailer = %q", got)
+	}
+}
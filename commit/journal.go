@@ -0,0 +1,154 @@
+package commit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dinoDanic/diny/git"
+	"github.com/dinoDanic/diny/logging"
+)
+
+const (
+	journalFile = "journal.yaml"
+	journalMax  = 50
+)
+
+// JournalEntry is a commit, amend, split or reword diny made, kept so
+// `diny undo` can take it back.
+type JournalEntry struct {
+	Kind     string    `yaml:"kind"`  // commit, amend, split or reword
+	Base     string    `yaml:"base"`  // HEAD before
+	Head     string    `yaml:"head"`  // HEAD after
+	Index    string    `yaml:"index"` // tree of the index before
+	Subjects []string  `yaml:"subjects"`
+	Pushed   bool      `yaml:"pushed"`
+	Time     time.Time `yaml:"time"`
+}
+
+// BeginJournal notes HEAD and the index before diny commits. It returns nil
+// when they can't be read, e.g. before the first commit, in which case
+// nothing is journaled.
+func BeginJournal(kind string) *JournalEntry {
+	base, err := git.GetHead()
+	if err != nil {
+		return nil
+	}
+	index, err := git.WriteTree()
+	if err != nil {
+		logging.Warn("journal: failed to snapshot the index", "error", err.Error())
+		return nil
+	}
+	return &JournalEntry{Kind: kind, Base: base, Index: index}
+}
+
+// Record adds the entry to the journal once its commits have landed.
+// Journaling is best effort and never fails a commit.
+func (e *JournalEntry) Record(messages []string, pushed bool) {
+	if e == nil {
+		return
+	}
+	head, err := git.GetHead()
+	if err != nil {
+		return
+	}
+	e.Head = head
+	e.Pushed = pushed
+	e.Time = time.Now()
+	e.Subjects = nil
+	for _, m := range messages {
+		e.Subjects = append(e.Subjects, strings.SplitN(m, "\n", 2)[0])
+	}
+
+	entries, err := loadJournal()
+	if err != nil {
+		logging.Warn("journal: failed to read", "error", err.Error())
+	}
+	entries = append(entries, *e)
+	if len(entries) > journalMax {
+		entries = entries[len(entries)-journalMax:]
+	}
+	if err := writeState(journalFile, entries); err != nil {
+		logging.Warn("journal: failed to write", "error", err.Error())
+	}
+}
+
+// MarkPushed flags the journal entry that ended at head as pushed.
+func MarkPushed(head string) {
+	entries, err := loadJournal()
+	if err != nil || len(entries) == 0 {
+		return
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Head == head {
+			entries[i].Pushed = true
+			if err := writeState(journalFile, entries); err != nil {
+				logging.Warn("journal: failed to write", "error", err.Error())
+			}
+			return
+		}
+	}
+}
+
+// LastJournalEntry returns the most recent entry, or nil if there is none.
+func LastJournalEntry() (*JournalEntry, error) {
+	entries, err := loadJournal()
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[len(entries)-1], nil
+}
+
+func loadJournal() ([]JournalEntry, error) {
+	var entries []JournalEntry
+	if _, err := readState(journalFile, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Undo takes back the last journaled commit, amend, split or reword: HEAD
+// is soft reset to where it was and the index is restored as it was staged,
+// so the changes are ready to commit again. It refuses when HEAD has moved
+// since or the commits have been pushed.
+func Undo() (*JournalEntry, error) {
+	entries, err := loadJournal()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("nothing to undo: diny has no record of a commit in this repository")
+	}
+	e := entries[len(entries)-1]
+
+	head, err := git.GetHead()
+	if err != nil {
+		return nil, err
+	}
+	if head != e.Head {
+		return nil, fmt.Errorf("HEAD moved since diny's last %s (expected %s, found %s); undo it with git instead", e.Kind, short(e.Head), short(head))
+	}
+	if e.Pushed {
+		return nil, fmt.Errorf("the last %s was pushed; undoing it would rewrite published history, use git revert instead", e.Kind)
+	}
+	if remotes, err := git.GetRemoteBranchesContaining(e.Head); err != nil {
+		return nil, err
+	} else if len(remotes) > 0 {
+		return nil, fmt.Errorf("the last %s is already on %s; undoing it would rewrite published history, use git revert instead", e.Kind, strings.Join(remotes, ", "))
+	}
+
+	if err := git.ResetSoft(e.Base); err != nil {
+		return nil, err
+	}
+	if err := git.ReadTree(e.Index); err != nil {
+		return nil, err
+	}
+	if err := writeState(journalFile, entries[:len(entries)-1]); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func short(hash string) string {
+	return hash[:min(7, len(hash))]
+}
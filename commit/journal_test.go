@@ -0,0 +1,64 @@
+package commit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUndo(t *testing.T) {
+	r := newTestRepo(t)
+	r.run("commit", "-q", "--allow-empty", "-m", "base")
+	base := r.run("rev-parse", "HEAD")
+
+	if _, err := Undo(); err == nil {
+		t.Errorf("Undo with an empty journal should fail")
+	}
+
+	// Partly staged: the undo must bring back exactly what was staged.
+	r.write("a.txt", "one\n")
+	r.run("add", "a.txt")
+	r.write("a.txt", "one\ntwo\n")
+	staged := r.run("write-tree")
+	if _, err := TryCommit("feat: add a\n\nbody", false, false, nil); err != nil {
+		t.Fatalf("TryCommit: %v", err)
+	}
+
+	entry, err := Undo()
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if entry.Kind != "commit" || len(entry.Subjects) != 1 || entry.Subjects[0] != "feat: add a" {
+		t.Errorf("entry = %+v", entry)
+	}
+	if got := r.run("rev-parse", "HEAD"); got != base {
+		t.Errorf("HEAD = %s, want %s", got, base)
+	}
+	if got := r.run("write-tree"); got != staged {
+		t.Errorf("index = %s, want %s", got, staged)
+	}
+	if data, _ := os.ReadFile(filepath.Join(r.dir, "a.txt")); string(data) != "one\ntwo\n" {
+		t.Errorf("worktree changed: %q", data)
+	}
+
+	// HEAD moved since.
+	if _, err := TryCommit("feat: add a", false, false, nil); err != nil {
+		t.Fatalf("TryCommit: %v", err)
+	}
+	r.run("commit", "-q", "--allow-empty", "-m", "by hand")
+	if _, err := Undo(); err == nil || !strings.Contains(err.Error(), "HEAD moved") {
+		t.Errorf("Undo after HEAD moved = %v", err)
+	}
+	r.run("reset", "-q", "--soft", "HEAD~1")
+
+	// Pushed.
+	remote := t.TempDir()
+	r.run("init", "-q", "--bare", remote)
+	r.run("remote", "add", "origin", remote)
+	r.run("push", "-q", "origin", "HEAD:refs/heads/main")
+	r.run("fetch", "-q", "origin")
+	if _, err := Undo(); err == nil || !strings.Contains(err.Error(), "origin/main") {
+		t.Errorf("Undo of a pushed commit = %v", err)
+	}
+}
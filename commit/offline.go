@@ -0,0 +1,308 @@
+package commit
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/git"
+)
+
+// Offline generation builds a commit message from file statuses and the
+// diffstat alone, so diny stays usable when no backend can be reached. The
+// output is deterministic: the same staged changes always give the same
+// message.
+
+type fileCategory int
+
+const (
+	categoryCode fileCategory = iota
+	categoryTest
+	categoryDocs
+	categoryConfig
+	categoryDeps
+)
+
+var depFiles = map[string]bool{
+	"go.mod": true, "go.sum": true,
+	"package.json": true, "package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true, "bun.lockb": true,
+	"cargo.toml": true, "cargo.lock": true,
+	"gemfile": true, "gemfile.lock": true,
+	"poetry.lock": true, "pipfile": true, "pipfile.lock": true, "uv.lock": true,
+	"composer.json": true, "composer.lock": true,
+}
+
+var configExts = map[string]bool{
+	".yaml": true, ".yml": true, ".toml": true, ".ini": true, ".cfg": true, ".conf": true, ".json": true, ".env": true,
+}
+
+var emojiByType = map[string]string{
+	"feat":     "✨",
+	"fix":      "🐛",
+	"docs":     "📝",
+	"style":    "💄",
+	"refactor": "♻️",
+	"perf":     "⚡",
+	"test":     "✅",
+	"chore":    "🔧",
+}
+
+func categorize(p string) fileCategory {
+	lower := strings.ToLower(p)
+	base := path.Base(lower)
+	ext := path.Ext(base)
+
+	switch {
+	case depFiles[base] || (strings.HasPrefix(base, "requirements") && ext == ".txt"):
+		return categoryDeps
+	case strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") || hasDir(lower, "test", "tests", "__tests__", "spec"):
+		return categoryTest
+	case ext == ".md" || ext == ".mdx" || ext == ".rst" || ext == ".adoc" || base == "license" || hasDir(lower, "docs", "doc"):
+		return categoryDocs
+	case configExts[ext] || strings.HasPrefix(base, ".") || base == "makefile" || base == "dockerfile" || hasDir(lower, ".github"):
+		return categoryConfig
+	}
+	return categoryCode
+}
+
+func hasDir(p string, names ...string) bool {
+	dirs := strings.Split(path.Dir(p), "/")
+	for _, d := range dirs {
+		for _, n := range names {
+			if d == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// subjectName turns a path into the noun used in the subject, e.g.
+// "auth/login_test.go" -> "login".
+func subjectName(p string) string {
+	base := path.Base(p)
+	if base == "go.mod" || base == "go.sum" {
+		return base
+	}
+	name := strings.TrimSuffix(base, path.Ext(base))
+	for _, suffix := range []string{"_test", ".test", ".spec"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	name = strings.TrimPrefix(name, "test_")
+	if name == "" {
+		return base
+	}
+	return name
+}
+
+// offlineScope picks the conventional-commit scope: the nearest directory
+// shared by every file, ignoring generic roots like src/ or internal/.
+func offlineScope(files []git.StagedFile, cat fileCategory, mixed bool) string {
+	if !mixed && cat == categoryDeps {
+		return "deps"
+	}
+
+	common := strings.Split(path.Dir(files[0].Path), "/")
+	for _, f := range files[1:] {
+		parts := strings.Split(path.Dir(f.Path), "/")
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+		common = common[:n]
+	}
+
+	generic := map[string]bool{".": true, "src": true, "internal": true, "pkg": true, "lib": true, "app": true, "test": true, "tests": true, "__tests__": true, "docs": true}
+	for i := len(common) - 1; i >= 0; i-- {
+		if !generic[common[i]] {
+			return common[i]
+		}
+	}
+	return ""
+}
+
+// BuildOfflineMessage is the pure part of CreateOfflineCommitMessage.
+func BuildOfflineMessage(files []git.StagedFile, stats []git.FileStat, cfg config.CommitConfig) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	cat := categorize(files[0].Path)
+	mixed := false
+	for _, f := range files[1:] {
+		if categorize(f.Path) != cat {
+			mixed = true
+			break
+		}
+	}
+	if mixed {
+		cat = categoryCode
+	}
+
+	allStatus := func(s string) bool {
+		for _, f := range files {
+			if f.Status != s {
+				return false
+			}
+		}
+		return true
+	}
+	anyStatus := func(s string) bool {
+		for _, f := range files {
+			if f.Status == s {
+				return true
+			}
+		}
+		return false
+	}
+
+	verb := "update"
+	switch {
+	case allStatus("A"):
+		verb = "add"
+	case allStatus("D"):
+		verb = "remove"
+	case allStatus("R"):
+		verb = "rename"
+	}
+
+	var commitType string
+	switch cat {
+	case categoryTest:
+		commitType = "test"
+	case categoryDocs:
+		commitType = "docs"
+	case categoryConfig, categoryDeps:
+		commitType = "chore"
+	default:
+		switch {
+		case anyStatus("A") || anyStatus("C"):
+			commitType = "feat"
+		case allStatus("D") || allStatus("R"):
+			commitType = "refactor"
+		default:
+			commitType = "chore"
+		}
+	}
+
+	// Object of the sentence: up to two file names, then a count.
+	var names []string
+	seen := map[string]bool{}
+	for _, f := range files {
+		n := subjectName(f.Path)
+		if !seen[n] {
+			seen[n] = true
+			names = append(names, n)
+		}
+	}
+	var object string
+	switch {
+	case !mixed && cat == categoryDeps:
+		object = "dependencies"
+	case len(names) == 1:
+		object = names[0]
+	case len(names) == 2:
+		object = names[0] + " and " + names[1]
+	default:
+		object = fmt.Sprintf("%s, %s and %d more", names[0], names[1], len(names)-2)
+	}
+	if !mixed && cat == categoryTest {
+		object += " tests"
+	}
+
+	maxSubject := 60
+	switch cfg.Length {
+	case config.Normal:
+		maxSubject = 70
+	case config.Long:
+		maxSubject = 80
+	}
+
+	build := func(object string) string {
+		var subject string
+		if cfg.Conventional {
+			scope := offlineScope(files, cat, mixed)
+			if scope != "" {
+				subject = fmt.Sprintf("%s(%s): %s %s", commitType, scope, verb, object)
+			} else {
+				subject = fmt.Sprintf("%s: %s %s", commitType, verb, object)
+			}
+		} else {
+			subject = strings.ToUpper(verb[:1]) + verb[1:] + " " + object
+		}
+		if cfg.Emoji {
+			subject = emojiByType[commitType] + " " + subject
+		}
+		return subject
+	}
+
+	subject := build(object)
+	if len([]rune(subject)) > maxSubject {
+		subject = build(fmt.Sprintf("%d files", len(files)))
+	}
+
+	if cfg.Length == config.Short || cfg.Length == "" {
+		return subject
+	}
+	return subject + "\n\n" + offlineBody(files, stats, cfg.Length)
+}
+
+func offlineBody(files []git.StagedFile, stats []git.FileStat, length config.Length) string {
+	var added, deleted int
+	byPath := map[string]git.FileStat{}
+	for _, st := range stats {
+		added += st.Added
+		deleted += st.Deleted
+		byPath[st.Path] = st
+	}
+
+	noun := "files"
+	if len(files) == 1 {
+		noun = "file"
+	}
+	bullets := []string{fmt.Sprintf("- %d %s changed, %d insertions(+), %d deletions(-)", len(files), noun, added, deleted)}
+	if length != config.Long {
+		return bullets[0]
+	}
+
+	// Long messages list the most-changed files.
+	sorted := append([]git.StagedFile(nil), files...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := byPath[sorted[i].Path], byPath[sorted[j].Path]
+		return a.Added+a.Deleted > b.Added+b.Deleted
+	})
+	const maxFiles = 5
+	for i, f := range sorted {
+		if i == maxFiles {
+			bullets = append(bullets, fmt.Sprintf("- and %d more", len(sorted)-maxFiles))
+			break
+		}
+		st := byPath[f.Path]
+		if st.Binary {
+			bullets = append(bullets, fmt.Sprintf("- %s %s (binary)", f.Status, f.Path))
+		} else {
+			bullets = append(bullets, fmt.Sprintf("- %s %s (+%d -%d)", f.Status, f.Path, st.Added, st.Deleted))
+		}
+	}
+	return strings.Join(bullets, "\n")
+}
+
+// CreateOfflineCommitMessage builds a commit message for the staged changes
+// without contacting any backend.
+func CreateOfflineCommitMessage(cfg *config.Config) (string, error) {
+	files, err := git.GetStagedFiles()
+	if err != nil {
+		return "", fmt.Errorf("failed to get staged files: %w", err)
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no staged changes")
+	}
+	stats, err := git.GetStagedStats()
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff stats: %w", err)
+	}
+	return BuildOfflineMessage(files, stats, cfg.Commit), nil
+}
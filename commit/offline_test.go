@@ -0,0 +1,73 @@
+package commit
+
+import (
+	"testing"
+
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/git"
+)
+
+func TestBuildOfflineMessage(t *testing.T) {
+	conventional := config.CommitConfig{Conventional: true, Length: config.Short}
+
+	cases := []struct {
+		name  string
+		files []git.StagedFile
+		cfg   config.CommitConfig
+		want  string
+	}{
+		{
+			name:  "new tests",
+			files: []git.StagedFile{{Status: "A", Path: "auth/login_test.go"}},
+			cfg:   conventional,
+			want:  "test(auth): add login tests",
+		},
+		{
+			name:  "docs",
+			files: []git.StagedFile{{Status: "M", Path: "README.md"}, {Status: "M", Path: "docs/setup.md"}},
+			cfg:   conventional,
+			want:  "docs: update README and setup",
+		},
+		{
+			name:  "dependencies",
+			files: []git.StagedFile{{Status: "M", Path: "go.mod"}, {Status: "M", Path: "go.sum"}},
+			cfg:   conventional,
+			want:  "chore(deps): update dependencies",
+		},
+		{
+			name: "new code under src",
+			files: []git.StagedFile{
+				{Status: "A", Path: "src/billing/invoice.ts"},
+				{Status: "M", Path: "src/billing/index.ts"},
+				{Status: "A", Path: "src/billing/__tests__/invoice.test.ts"},
+			},
+			cfg:  conventional,
+			want: "feat(billing): update invoice and index",
+		},
+		{
+			name:  "plain with emoji",
+			files: []git.StagedFile{{Status: "D", Path: "legacy/old.go"}},
+			cfg:   config.CommitConfig{Emoji: true, Length: config.Short},
+			want:  "♻️ Remove old",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := BuildOfflineMessage(tc.files, nil, tc.cfg); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildOfflineMessage_Body(t *testing.T) {
+	files := []git.StagedFile{{Status: "M", Path: "config/config.go"}}
+	stats := []git.FileStat{{Path: "config/config.go", Added: 12, Deleted: 3}}
+
+	got := BuildOfflineMessage(files, stats, config.CommitConfig{Conventional: true, Length: config.Normal})
+	want := "chore(config): update config\n\n- 1 file changed, 12 insertions(+), 3 deletions(-)"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,103 @@
+package commit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dinoDanic/diny/git"
+)
+
+// RewordPlan is a checked range of commits on the current branch whose
+// messages `diny reword` may rewrite.
+type RewordPlan struct {
+	Range   string
+	Base    string // rebase onto this; "" for the root
+	Commits []git.RangeCommit
+}
+
+// PlanReword resolves rng, a revision range or a single commit meaning
+// <commit>..HEAD, and checks it can be reworded: every commit must be on
+// the current branch, and unless force is set none may be a merge or
+// already pushed to a remote branch.
+func PlanReword(rng string, force bool) (*RewordPlan, error) {
+	if !strings.Contains(rng, "..") {
+		rng += "..HEAD"
+	}
+	commits, err := git.GetRangeCommits(rng)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits in %s", rng)
+	}
+
+	p := &RewordPlan{Range: rng, Commits: commits}
+	if parents := commits[0].Parents; len(parents) > 0 {
+		p.Base = parents[0]
+	}
+	for _, c := range commits {
+		onBranch, err := git.IsAncestor(c.Hash, "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		if !onBranch {
+			return nil, fmt.Errorf("%s is not on the current branch; reword can only rewrite history leading to HEAD", short(c.Hash))
+		}
+		if p.Base != "" {
+			if after, err := git.IsAncestor(p.Base, c.Hash); err != nil {
+				return nil, err
+			} else if !after {
+				return nil, fmt.Errorf("%s does not build on %s; pick a range along one line of history", short(c.Hash), short(p.Base))
+			}
+		}
+		if c.IsMerge() && !force {
+			return nil, fmt.Errorf("%s is a merge commit; rerun with --force to reword around it (merges keep their messages)", short(c.Hash))
+		}
+	}
+
+	// Anything pushed includes the oldest commit, so it is enough to check.
+	remotes, err := git.GetRemoteBranchesContaining(commits[0].Hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(remotes) > 0 && !force {
+		return nil, fmt.Errorf("%s is already on %s; rewording rewrites published history, rerun with --force to do it anyway", short(commits[0].Hash), strings.Join(remotes, ", "))
+	}
+	return p, nil
+}
+
+// HasMerges reports whether the range contains a merge commit.
+func (p *RewordPlan) HasMerges() bool {
+	for _, c := range p.Commits {
+		if c.IsMerge() {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply rewrites the commits whose message in messages (one per commit,
+// in plan order) differs from the current one, and reports how many it
+// changed. The rewrite is journaled, so `diny undo` can take it back.
+func (p *RewordPlan) Apply(messages []string) (int, error) {
+	changed := map[string]string{}
+	var subjects []string
+	for i, c := range p.Commits {
+		msg := strings.TrimSpace(messages[i])
+		if c.IsMerge() || msg == "" || msg == c.Message {
+			continue
+		}
+		changed[c.Hash] = msg
+		subjects = append(subjects, msg)
+	}
+	if len(changed) == 0 {
+		return 0, nil
+	}
+
+	journal := BeginJournal("reword")
+	if err := git.Reword(p.Base, changed, p.HasMerges()); err != nil {
+		return 0, err
+	}
+	journal.Record(subjects, false)
+	return len(changed), nil
+}
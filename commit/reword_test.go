@@ -0,0 +1,94 @@
+package commit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReword(t *testing.T) {
+	r := newTestRepo(t)
+	commitFile := func(name, msg string) {
+		t.Helper()
+		r.write(name, name+"\n")
+		r.run("add", name)
+		r.run("commit", "-q", "-m", msg)
+	}
+	commitFile("base.txt", "base")
+	commitFile("a.txt", "wip")
+	commitFile("b.txt", "fix")
+	commitFile("c.txt", "more")
+	// Unstaged work survives the rebase.
+	r.write("a.txt", "dirty\n")
+
+	plan, err := PlanReword("HEAD~3", false)
+	if err != nil {
+		t.Fatalf("PlanReword: %v", err)
+	}
+	if len(plan.Commits) != 3 || plan.Commits[0].Subject() != "wip" {
+		t.Fatalf("plan = %+v", plan.Commits)
+	}
+	changed, err := plan.Apply([]string{"feat: add a\n\n#42 stays", "fix", "feat: add c"})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if changed != 2 {
+		t.Errorf("changed = %d, want 2", changed)
+	}
+	if got := r.run("log", "--format=%s", "-4"); got != "feat: add c\nfix\nfeat: add a\nbase" {
+		t.Errorf("log =\n%s", got)
+	}
+	if got := r.run("log", "-1", "--format=%b", "HEAD~2"); got != "#42 stays" {
+		t.Errorf("body = %q", got)
+	}
+	if data, _ := os.ReadFile(filepath.Join(r.dir, "a.txt")); string(data) != "dirty\n" {
+		t.Errorf("worktree change lost: %q", data)
+	}
+
+	if _, err := Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if got := r.run("log", "--format=%s", "-4"); got != "more\nfix\nwip\nbase" {
+		t.Errorf("log after undo =\n%s", got)
+	}
+
+	// Merges are refused without --force.
+	r.run("checkout", "-q", "-b", "side", "HEAD~1")
+	commitFile("side.txt", "side")
+	r.run("checkout", "-q", "main")
+	r.run("merge", "-q", "--no-ff", "-m", "merge side", "side")
+	if _, err := PlanReword("HEAD~2", false); err == nil || !strings.Contains(err.Error(), "merge commit") {
+		t.Errorf("PlanReword through a merge = %v", err)
+	}
+	plan, err = PlanReword("HEAD~2", true)
+	if err != nil {
+		t.Fatalf("PlanReword --force: %v", err)
+	}
+	messages := make([]string, len(plan.Commits))
+	for i, c := range plan.Commits {
+		messages[i] = c.Message
+		if c.Subject() == "side" {
+			messages[i] = "feat: add side"
+		}
+	}
+	if _, err := plan.Apply(messages); err != nil {
+		t.Fatalf("Apply through a merge: %v", err)
+	}
+	if got := r.run("log", "--format=%s", "-3", "--topo-order"); got != "merge side\nfeat: add side\nmore" {
+		t.Errorf("log after rewording through a merge =\n%s", got)
+	}
+
+	// So are pushed commits.
+	commitFile("d.txt", "d")
+	remote := t.TempDir()
+	r.run("init", "-q", "--bare", remote)
+	r.run("remote", "add", "origin", remote)
+	r.run("push", "-q", "origin", "main")
+	if _, err := PlanReword("HEAD~1", false); err == nil || !strings.Contains(err.Error(), "origin/main") {
+		t.Errorf("PlanReword of a pushed range = %v", err)
+	}
+	if _, err := PlanReword("HEAD~1", true); err != nil {
+		t.Errorf("PlanReword --force: %v", err)
+	}
+}
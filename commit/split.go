@@ -1,7 +1,9 @@
 package commit
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -17,8 +19,8 @@ type SplitGroup = groq.SplitGroup
 type SplitRequestExtras = groq.RequestExtras
 
 // CreateSplitPlan asks the backend to group the staged diff into multiple commits.
-func CreateSplitPlan(gitDiff string, cfg *config.Config, extras *SplitRequestExtras) ([]SplitGroup, error) {
-	return groq.CreateSplitPlanWithGroq(gitDiff, cfg, extras)
+func CreateSplitPlan(ctx context.Context, gitDiff string, cfg *config.Config, extras *SplitRequestExtras) ([]SplitGroup, error) {
+	return groq.CreateSplitPlanWithGroq(ctx, gitDiff, cfg, extras)
 }
 
 // normalizePlanPath strips bogus prefixes that LLMs sometimes add to file
@@ -39,12 +41,16 @@ func normalizePlanPath(p string) string {
 	}
 }
 
+// HunkRef is an alias to the transport type for ergonomic use across the TUI.
+type HunkRef = groq.HunkRef
+
 // ValidatePlan checks that every staged file appears in exactly one group and
-// no group references files that are not currently staged. File paths returned
-// by the model are normalized first (see normalizePlanPath) and the plan is
-// mutated in place to use the canonical staged path, so downstream `git add`
-// calls receive valid paths. Returns the (possibly rewritten) plan.
-func ValidatePlan(plan []SplitGroup, staged []git.StagedFile) error {
+// no group references files that are not currently staged. A file may
+// instead be split by hunk, in which case each of its hunks in diff must be
+// assigned exactly once. File paths returned by the model are normalized
+// first (see normalizePlanPath) and the plan is mutated in place to use the
+// canonical staged path, so downstream `git add` calls receive valid paths.
+func ValidatePlan(plan []SplitGroup, staged []git.StagedFile, diff string) error {
 	if len(plan) == 0 {
 		return fmt.Errorf("plan has no groups")
 	}
@@ -53,21 +59,27 @@ func ValidatePlan(plan []SplitGroup, staged []git.StagedFile) error {
 	for _, f := range staged {
 		stagedSet[f.Path] = struct{}{}
 	}
+	resolve := func(f string) (string, error) {
+		if _, ok := stagedSet[f]; ok {
+			return f, nil
+		}
+		if canonical := normalizePlanPath(f); canonical != f {
+			if _, ok := stagedSet[canonical]; ok {
+				return canonical, nil
+			}
+		}
+		return "", fmt.Errorf("plan references %q which is not staged", f)
+	}
 
 	seen := make(map[string]int, len(staged))
 	for gi := range plan {
 		g := &plan[gi]
 		for fi, f := range g.Files {
-			canonical := f
-			if _, ok := stagedSet[canonical]; !ok {
-				canonical = normalizePlanPath(f)
-			}
-			if _, ok := stagedSet[canonical]; !ok {
-				return fmt.Errorf("plan references %q which is not staged", f)
-			}
-			if canonical != f {
-				g.Files[fi] = canonical
+			canonical, err := resolve(f)
+			if err != nil {
+				return err
 			}
+			g.Files[fi] = canonical
 			if groupIdx, dup := seen[canonical]; dup {
 				return fmt.Errorf("plan assigns %q to groups %d and %d", canonical, groupIdx+1, g.Order)
 			}
@@ -75,6 +87,41 @@ func ValidatePlan(plan []SplitGroup, staged []git.StagedFile) error {
 		}
 	}
 
+	hunkCounts := map[string]int{}
+	for _, fd := range git.ParseDiff(diff) {
+		hunkCounts[fd.Path] = len(fd.Hunks)
+	}
+	hunkGroups := map[string]map[int]int{}
+	for gi := range plan {
+		g := &plan[gi]
+		for hi, h := range g.Hunks {
+			canonical, err := resolve(h.File)
+			if err != nil {
+				return err
+			}
+			g.Hunks[hi].File = canonical
+			if _, whole := seen[canonical]; whole {
+				return fmt.Errorf("plan assigns %q both whole and by hunk", canonical)
+			}
+			if n := hunkCounts[canonical]; h.Hunk < 1 || h.Hunk > n {
+				return fmt.Errorf("plan references hunk %d of %q, which has %d", h.Hunk, canonical, n)
+			}
+			if hunkGroups[canonical] == nil {
+				hunkGroups[canonical] = map[int]int{}
+			}
+			if groupIdx, dup := hunkGroups[canonical][h.Hunk]; dup {
+				return fmt.Errorf("plan assigns hunk %d of %q to groups %d and %d", h.Hunk, canonical, groupIdx+1, g.Order)
+			}
+			hunkGroups[canonical][h.Hunk] = g.Order - 1
+		}
+	}
+	for path, groups := range hunkGroups {
+		if n := hunkCounts[path]; len(groups) < n {
+			return fmt.Errorf("plan assigns %d of %d hunks of %q", len(groups), n, path)
+		}
+		seen[path] = -1
+	}
+
 	var missing []string
 	for _, f := range staged {
 		if _, ok := seen[f.Path]; !ok {
@@ -89,6 +136,17 @@ func ValidatePlan(plan []SplitGroup, staged []git.StagedFile) error {
 	return nil
 }
 
+// GroupFiles lists the files a group touches, whole or through hunks.
+func GroupFiles(g SplitGroup) []string {
+	files := append([]string(nil), g.Files...)
+	for _, h := range g.Hunks {
+		if !slices.Contains(files, h.File) {
+			files = append(files, h.File)
+		}
+	}
+	return files
+}
+
 // NormalizePlan sorts groups by Order and renumbers them 1..N.
 func NormalizePlan(plan []SplitGroup) []SplitGroup {
 	out := make([]SplitGroup, len(plan))
@@ -1,9 +1,15 @@
 package commit
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/dinoDanic/diny/config"
 	"github.com/dinoDanic/diny/git"
 	"github.com/dinoDanic/diny/groq"
 )
@@ -47,7 +53,7 @@ func TestValidatePlan_StripsBogusPrefix(t *testing.T) {
 			Files:   []string{"i/features/auth/login.tsx", "a/features/auth/register.tsx"},
 		},
 	}
-	if err := ValidatePlan(plan, staged); err != nil {
+	if err := ValidatePlan(plan, staged, ""); err != nil {
 		t.Fatalf("ValidatePlan returned error: %v", err)
 	}
 	if plan[0].Files[0] != "features/auth/login.tsx" {
@@ -70,7 +76,7 @@ func TestValidatePlan_UnresolvablePathStillErrors(t *testing.T) {
 			Files:   []string{"features/auth/nonexistent.tsx"},
 		},
 	}
-	err := ValidatePlan(plan, staged)
+	err := ValidatePlan(plan, staged, "")
 	if err == nil {
 		t.Fatal("expected error for unresolvable path")
 	}
@@ -87,7 +93,7 @@ func TestValidatePlan_DuplicateAcrossGroupsAfterNormalize(t *testing.T) {
 		{Order: 1, Type: "feat", Message: "a", Files: []string{"features/auth/login.tsx"}},
 		{Order: 2, Type: "feat", Message: "b", Files: []string{"a/features/auth/login.tsx"}},
 	}
-	err := ValidatePlan(plan, staged)
+	err := ValidatePlan(plan, staged, "")
 	if err == nil {
 		t.Fatal("expected duplicate error")
 	}
@@ -95,3 +101,197 @@ func TestValidatePlan_DuplicateAcrossGroupsAfterNormalize(t *testing.T) {
 		t.Errorf("unexpected error message: %v", err)
 	}
 }
+
+func TestCreateSplitPlan_OpenAICompatible(t *testing.T) {
+	t.Setenv("DINY_TEST_KEY", "sk-test")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+		var req struct {
+			Model          string `json:"model"`
+			ResponseFormat struct {
+				Type string `json:"type"`
+			} `json:"response_format"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req.Model != "test-model" {
+			t.Errorf("model = %q, want test-model", req.Model)
+		}
+		if req.ResponseFormat.Type != "json_schema" {
+			t.Errorf("response_format.type = %q, want json_schema", req.ResponseFormat.Type)
+		}
+
+		plan := `{"groups":[` +
+			`{"order":2,"type":"test","message":"add login tests","files":["b/auth/login_test.go"]},` +
+			`{"order":1,"type":"feat","message":"add login","files":["auth/login.go"]}]}`
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]string{"role": "assistant", "content": plan}},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		Theme:  "catppuccin",
+		Commit: config.CommitConfig{Tone: config.Casual, Length: config.Short},
+		Provider: config.ProviderConfig{
+			Name: config.ProviderOpenAI,
+			OpenAI: config.OpenAIConfig{
+				BaseURL:   srv.URL + "/v1",
+				Model:     "test-model",
+				APIKeyEnv: "DINY_TEST_KEY",
+			},
+		},
+	}
+
+	plan, err := CreateSplitPlan(context.Background(), "diff --git a/auth/login.go b/auth/login.go", cfg, nil)
+	if err != nil {
+		t.Fatalf("CreateSplitPlan returned error: %v", err)
+	}
+	plan = NormalizePlan(plan)
+
+	staged := []git.StagedFile{
+		{Status: "A", Path: "auth/login.go"},
+		{Status: "A", Path: "auth/login_test.go"},
+	}
+	if err := ValidatePlan(plan, staged, ""); err != nil {
+		t.Fatalf("ValidatePlan returned error: %v", err)
+	}
+	if plan[0].Message != "add login" || plan[1].Files[0] != "auth/login_test.go" {
+		t.Errorf("unexpected plan: %+v", plan)
+	}
+}
+
+func TestCreateSplitPlan_OpenAIMissingKey(t *testing.T) {
+	t.Setenv("DINY_TEST_KEY", "")
+
+	cfg := &config.Config{
+		Provider: config.ProviderConfig{
+			Name: config.ProviderOpenAI,
+			OpenAI: config.OpenAIConfig{
+				BaseURL:   "http://127.0.0.1:0/v1",
+				Model:     "test-model",
+				APIKeyEnv: "DINY_TEST_KEY",
+			},
+		},
+	}
+
+	_, err := CreateSplitPlan(context.Background(), "diff", cfg, nil)
+	if err == nil || !strings.Contains(err.Error(), "DINY_TEST_KEY") {
+		t.Fatalf("expected missing key error, got %v", err)
+	}
+}
+
+func TestCreateSplitPlan_Cancelled(t *testing.T) {
+	t.Setenv("DINY_TEST_KEY", "sk-test")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cfg := &config.Config{
+		Provider: config.ProviderConfig{
+			Name: config.ProviderOpenAI,
+			OpenAI: config.OpenAIConfig{
+				BaseURL:   srv.URL + "/v1",
+				Model:     "test-model",
+				APIKeyEnv: "DINY_TEST_KEY",
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	_, err := CreateSplitPlan(ctx, "diff", cfg, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+const twoHunkDiff = `diff --git a/app/server.go b/app/server.go
+index 1111111..2222222 100644
+--- a/app/server.go
++++ b/app/server.go
+@@ -1,3 +1,3 @@
+ package app
+-var port = 80
++var port = 8080
+ 
+@@ -20,3 +20,4 @@ func run() {
+ 	start()
++	defer stop()
+ }
+diff --git a/README.md b/README.md
+index 3333333..4444444 100644
+--- a/README.md
++++ b/README.md
+@@ -1 +1 @@
+-old
++new
+`
+
+func TestValidatePlan_AssignsHunks(t *testing.T) {
+	staged := []git.StagedFile{
+		{Status: "M", Path: "app/server.go"},
+		{Status: "M", Path: "README.md"},
+	}
+	plan := []groq.SplitGroup{
+		{Order: 1, Type: "fix", Message: "fix port", Hunks: []groq.HunkRef{{File: "a/app/server.go", Hunk: 1}}},
+		{Order: 2, Type: "feat", Message: "stop on exit", Files: []string{"README.md"}, Hunks: []groq.HunkRef{{File: "app/server.go", Hunk: 2}}},
+	}
+	if err := ValidatePlan(plan, staged, twoHunkDiff); err != nil {
+		t.Fatalf("ValidatePlan returned error: %v", err)
+	}
+	if plan[0].Hunks[0].File != "app/server.go" {
+		t.Errorf("hunk path not rewritten: %q", plan[0].Hunks[0].File)
+	}
+	if got := GroupFiles(plan[1]); len(got) != 2 || got[1] != "app/server.go" {
+		t.Errorf("GroupFiles = %v", got)
+	}
+}
+
+func TestValidatePlan_HunkErrors(t *testing.T) {
+	staged := []git.StagedFile{
+		{Status: "M", Path: "app/server.go"},
+		{Status: "M", Path: "README.md"},
+	}
+	cases := map[string][]groq.SplitGroup{
+		"both whole and by hunk": {
+			{Order: 1, Files: []string{"app/server.go", "README.md"}},
+			{Order: 2, Hunks: []groq.HunkRef{{File: "app/server.go", Hunk: 2}}},
+		},
+		"which has 2": {
+			{Order: 1, Files: []string{"README.md"}, Hunks: []groq.HunkRef{{File: "app/server.go", Hunk: 1}, {File: "app/server.go", Hunk: 3}}},
+		},
+		"to groups 1 and 2": {
+			{Order: 1, Files: []string{"README.md"}, Hunks: []groq.HunkRef{{File: "app/server.go", Hunk: 1}, {File: "app/server.go", Hunk: 2}}},
+			{Order: 2, Hunks: []groq.HunkRef{{File: "app/server.go", Hunk: 2}}},
+		},
+		"assigns 1 of 2 hunks": {
+			{Order: 1, Files: []string{"README.md"}, Hunks: []groq.HunkRef{{File: "app/server.go", Hunk: 1}}},
+		},
+	}
+	for want, plan := range cases {
+		err := ValidatePlan(plan, staged, twoHunkDiff)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package commit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/dinoDanic/diny/git"
+	"github.com/dinoDanic/diny/logging"
+)
+
+// SplitRun is a split being committed group by group. When it stops with
+// groups left it is saved to <gitdir>/diny/split-state.yaml, so
+// `diny commit --continue-split` can pick up at the group that failed and
+// `--abort-split` can put HEAD and the index back.
+type SplitRun struct {
+	Plan     []SplitGroup      `yaml:"plan"`
+	Diff     string            `yaml:"diff"`     // the staged diff the plan's hunks refer to
+	Snapshot string            `yaml:"snapshot"` // tree of the index before the split
+	OrigHead string            `yaml:"orig_head"`
+	Head     string            `yaml:"head"` // HEAD after the last group that landed
+	Hashes   []string          `yaml:"hashes"`
+	Next     int               `yaml:"next"` // index of the group to commit next
+	NoVerify bool              `yaml:"no_verify"`
+	Push     bool              `yaml:"push"`
+	Trailers []string          `yaml:"trailers,omitempty"` // added to every group's message
+	Renames  map[string]string `yaml:"renames,omitempty"`  // staged renames, new path to old
+	Failure  string            `yaml:"failure,omitempty"`
+}
+
+// SplitError is a group that could not be staged or committed, usually
+// because a hook rejected it.
+type SplitError struct {
+	Group  int
+	Output string
+}
+
+func (e *SplitError) Error() string {
+	return fmt.Sprintf("group %d failed: %s", e.Group+1, e.Output)
+}
+
+// StartSplit snapshots the index and resets it to HEAD, ready to commit
+// plan. Every group is staged from the snapshot rather than the worktree,
+// so files the user only partly staged stay that way.
+func StartSplit(plan []SplitGroup, diff string, noVerify, push bool) (*SplitRun, error) {
+	snapshot, err := git.WriteTree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot the index: %w", err)
+	}
+	// On a branch with no commits yet head is "", and so is OrigHead.
+	head, err := git.GetHeadIfAny()
+	if err != nil {
+		return nil, err
+	}
+	staged, err := git.GetStagedFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staged files: %w", err)
+	}
+	var renames map[string]string
+	for _, f := range staged {
+		if f.From != "" && f.Status == "R" {
+			if renames == nil {
+				renames = map[string]string{}
+			}
+			renames[f.Path] = f.From
+		}
+	}
+	if err := git.Reset(); err != nil {
+		return nil, err
+	}
+	return &SplitRun{
+		Plan:     plan,
+		Diff:     diff,
+		Snapshot: snapshot,
+		OrigHead: head,
+		Head:     head,
+		NoVerify: noVerify,
+		Push:     push,
+		Renames:  renames,
+	}, nil
+}
+
+// Run commits the groups from Next on. It stops at the first group that
+// fails, returning a *SplitError, or before the next group once ctx is
+// cancelled. Either way the run is saved for --continue-split; a run that
+// finishes clears the saved state.
+func (r *SplitRun) Run(ctx context.Context) error {
+	return r.run(ctx, false)
+}
+
+// Continue resumes a saved run. Whatever is staged now is committed as the
+// failed group, so fixes made for a hook are included; with nothing staged
+// the group is staged again from the snapshot.
+func (r *SplitRun) Continue(ctx context.Context) error {
+	head, err := git.GetHeadIfAny()
+	if err != nil {
+		return err
+	}
+	if head != r.Head {
+		return fmt.Errorf("HEAD moved since the split stopped; commit or reset it, or run diny commit --abort-split")
+	}
+	staged, err := git.GetStagedFiles()
+	if err != nil {
+		return fmt.Errorf("failed to get staged files: %w", err)
+	}
+	return r.run(ctx, len(staged) > 0)
+}
+
+// Abort moves HEAD back to where the split started, keeping the worktree,
+// and restores the index from before the split.
+func (r *SplitRun) Abort() error {
+	head, err := git.GetHeadIfAny()
+	if err != nil {
+		return err
+	}
+	if head != r.Head {
+		return fmt.Errorf("HEAD moved since the split stopped; restore the index by hand with git read-tree %s", r.Snapshot)
+	}
+	switch {
+	case r.OrigHead != "":
+		err = git.ResetSoft(r.OrigHead)
+	case head != "":
+		// The split started before the first commit.
+		err = git.ResetUnborn()
+	}
+	if err != nil {
+		return err
+	}
+	if err := git.ReadTree(r.Snapshot); err != nil {
+		return err
+	}
+	return ClearSplitState()
+}
+
+func (r *SplitRun) run(ctx context.Context, keepStaged bool) error {
+	lastGroup := map[string]int{}
+	for i, g := range r.Plan {
+		for _, h := range g.Hunks {
+			lastGroup[h.File] = i
+		}
+	}
+	files := map[string]git.FileDiff{}
+	for _, fd := range git.ParseDiff(r.Diff) {
+		files[fd.Path] = fd
+	}
+
+	for r.Next < len(r.Plan) {
+		if ctx.Err() != nil {
+			r.Failure = ""
+			r.save()
+			return ctx.Err()
+		}
+		g := r.Plan[r.Next]
+		if !keepStaged {
+			if err := r.stage(g, r.Next, lastGroup, files); err != nil {
+				// Leave nothing half staged, so continuing stages the group afresh.
+				_ = git.Reset()
+				return r.fail(err.Error())
+			}
+		}
+		keepStaged = false
+
+		message, err := WithTrailers(g.Message, r.Trailers)
+		if err != nil {
+			return r.fail(err.Error())
+		}
+		if err := git.CommitIndex(message, r.NoVerify); err != nil {
+			return r.fail(err.Error())
+		}
+
+		head, err := git.GetHead()
+		if err != nil {
+			return r.fail(err.Error())
+		}
+		r.Head = head
+		r.Hashes = append(r.Hashes, short(head))
+		r.Next++
+	}
+
+	messages := make([]string, len(r.Plan))
+	for i, g := range r.Plan {
+		messages[i] = g.Message
+	}
+	// As with BeginJournal, a split of the first commit is not journaled.
+	if r.OrigHead != "" {
+		journal := &JournalEntry{Kind: "split", Base: r.OrigHead, Index: r.Snapshot}
+		journal.Record(messages, false)
+	}
+	if err := ClearSplitState(); err != nil {
+		logging.Warn("failed to clear split state", "error", err.Error())
+	}
+	return nil
+}
+
+// stage stages group idx: whole files as they are in the snapshot, and
+// hunks with git apply. The last group holding hunks of a file stages the
+// whole file, so changes the diff hides (whitespace-only lines) still land.
+func (r *SplitRun) stage(g SplitGroup, idx int, lastGroup map[string]int, files map[string]git.FileDiff) error {
+	whole := append([]string(nil), g.Files...)
+	hunks := map[string][]int{}
+	var partial []string
+	for _, h := range g.Hunks {
+		if lastGroup[h.File] == idx {
+			if !slices.Contains(whole, h.File) {
+				whole = append(whole, h.File)
+			}
+			continue
+		}
+		if _, ok := hunks[h.File]; !ok {
+			partial = append(partial, h.File)
+		}
+		hunks[h.File] = append(hunks[h.File], h.Hunk-1)
+	}
+
+	// A rename is staged as both halves, or the old path stays in the index.
+	for _, f := range whole {
+		if from, ok := r.Renames[f]; ok {
+			whole = append(whole, from)
+		}
+	}
+	if len(whole) > 0 {
+		if err := git.StageFrom(r.Snapshot, whole); err != nil {
+			return err
+		}
+	}
+	for _, f := range partial {
+		idxs := hunks[f]
+		sort.Ints(idxs)
+		if err := git.ApplyCached(files[f].Patch(idxs)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *SplitRun) fail(output string) error {
+	r.Failure = output
+	r.save()
+	return &SplitError{Group: r.Next, Output: output}
+}
+
+func (r *SplitRun) save() {
+	if err := SaveSplitState(r); err != nil {
+		logging.Warn("failed to save split state", "error", err.Error())
+	}
+}
+
+const splitStateFile = "split-state.yaml"
+
+// LoadSplitState returns the saved split, or nil if there is none.
+func LoadSplitState() (*SplitRun, error) {
+	var r SplitRun
+	ok, err := readState(splitStateFile, &r)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func SaveSplitState(r *SplitRun) error {
+	return writeState(splitStateFile, r)
+}
+
+// ClearSplitState removes the saved split, if any.
+func ClearSplitState() error {
+	return removeState(splitStateFile)
+}
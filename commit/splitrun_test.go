@@ -0,0 +1,188 @@
+package commit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitRun_ContinueAfterHookFailure(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("base.txt", "base\n")
+	r.run("add", "-A")
+	r.run("commit", "-q", "-m", "base")
+	orig := r.run("rev-parse", "HEAD")
+
+	r.write("a.txt", "a\n")
+	r.write("b.txt", "b\n")
+	r.run("add", "-A")
+	hook := filepath.Join(r.dir, ".git", "hooks", "pre-commit")
+	r.write(".git/hooks/pre-commit", "#!/bin/sh\ngit diff --cached --name-only | grep -q b.txt && exit 1\nexit 0\n")
+	if err := os.Chmod(hook, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := []SplitGroup{
+		{Order: 1, Type: "feat", Message: "add a", Files: []string{"a.txt"}},
+		{Order: 2, Type: "feat", Message: "add b", Files: []string{"b.txt"}},
+	}
+	sr, err := StartSplit(plan, "", false, false)
+	if err != nil {
+		t.Fatalf("StartSplit: %v", err)
+	}
+	var splitErr *SplitError
+	if err := sr.Run(context.Background()); !errors.As(err, &splitErr) || splitErr.Group != 1 {
+		t.Fatalf("Run = %v, want a failure in group 2", err)
+	}
+
+	saved, err := LoadSplitState()
+	if err != nil || saved == nil {
+		t.Fatalf("LoadSplitState = %v, %v", saved, err)
+	}
+	if saved.Next != 1 || len(saved.Hashes) != 1 || saved.OrigHead != orig {
+		t.Errorf("saved state = next %d, hashes %v, orig %s", saved.Next, saved.Hashes, saved.OrigHead)
+	}
+
+	if err := os.Remove(hook); err != nil {
+		t.Fatal(err)
+	}
+	if err := saved.Continue(context.Background()); err != nil {
+		t.Fatalf("Continue: %v", err)
+	}
+	if got := r.run("log", "--format=%s", "-3"); got != "add b\nadd a\nbase" {
+		t.Errorf("log =\n%s", got)
+	}
+	if again, _ := LoadSplitState(); again != nil {
+		t.Errorf("split state left behind after finishing")
+	}
+}
+
+func TestSplitRun_AbortRestoresHeadAndIndex(t *testing.T) {
+	r := newTestRepo(t)
+	r.run("commit", "-q", "--allow-empty", "-m", "base")
+	orig := r.run("rev-parse", "HEAD")
+	r.write("a.txt", "a.txt\n")
+	r.write("b.txt", "b.txt\n")
+	r.run("add", "-A")
+	tree := r.run("write-tree")
+
+	plan := []SplitGroup{
+		{Order: 1, Type: "feat", Message: "add a", Files: []string{"a.txt"}},
+		{Order: 2, Type: "feat", Message: "add b", Files: []string{"b.txt"}},
+	}
+	sr, err := StartSplit(plan, "", false, false)
+	if err != nil {
+		t.Fatalf("StartSplit: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sr.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+	r.run("commit", "-q", "--allow-empty", "-m", "in the way")
+	if err := sr.Abort(); err == nil {
+		t.Fatalf("Abort should refuse once HEAD has moved")
+	}
+	r.run("reset", "-q", "--soft", "HEAD~1")
+
+	if err := sr.Abort(); err != nil {
+		t.Fatalf("Abort: %v", err)
+	}
+	if got := r.run("rev-parse", "HEAD"); got != orig {
+		t.Errorf("HEAD = %s, want %s", got, orig)
+	}
+	if got := r.run("write-tree"); got != tree {
+		t.Errorf("index tree = %s, want %s", got, tree)
+	}
+}
+
+func TestSplitRun_FirstCommit(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("a.txt", "a\n")
+	r.write("b.txt", "b\n")
+	r.run("add", "-A")
+	tree := r.run("write-tree")
+
+	plan := []SplitGroup{
+		{Order: 1, Type: "feat", Message: "add a", Files: []string{"a.txt"}},
+		{Order: 2, Type: "feat", Message: "add b", Files: []string{"b.txt"}},
+	}
+	sr, err := StartSplit(plan, "", false, false)
+	if err != nil {
+		t.Fatalf("StartSplit: %v", err)
+	}
+	if sr.OrigHead != "" {
+		t.Errorf("OrigHead = %q, want empty before the first commit", sr.OrigHead)
+	}
+
+	// Stopped before any group: continuing still works from the unborn branch.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sr.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+	saved, err := LoadSplitState()
+	if err != nil || saved == nil {
+		t.Fatalf("LoadSplitState = %v, %v", saved, err)
+	}
+	if err := saved.Continue(context.Background()); err != nil {
+		t.Fatalf("Continue: %v", err)
+	}
+	if got := r.run("log", "--format=%s"); got != "add b\nadd a" {
+		t.Errorf("log =\n%s", got)
+	}
+
+	// Aborting a split of the first commit leaves the branch unborn again.
+	r.run("update-ref", "-d", "HEAD")
+	r.run("read-tree", tree)
+	sr, err = StartSplit(plan, "", false, false)
+	if err != nil {
+		t.Fatalf("StartSplit: %v", err)
+	}
+	sr.Plan[1].Files = []string{"missing.txt"}
+	var splitErr *SplitError
+	if err := sr.Run(context.Background()); !errors.As(err, &splitErr) {
+		t.Fatalf("Run = %v, want a failure in group 2", err)
+	}
+	if err := sr.Abort(); err != nil {
+		t.Fatalf("Abort: %v", err)
+	}
+	if out, err := exec.Command("git", "rev-parse", "--verify", "--quiet", "HEAD").Output(); err == nil {
+		t.Errorf("HEAD = %s, want no commits", out)
+	}
+	if got := r.run("write-tree"); got != tree {
+		t.Errorf("index tree = %s, want %s", got, tree)
+	}
+}
+
+func TestSplitRun_StagesRenames(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("old.txt", "some text that stays the same\n")
+	r.run("add", "-A")
+	r.run("commit", "-q", "-m", "base")
+
+	r.run("mv", "old.txt", "new.txt")
+	r.write("b.txt", "b\n")
+	r.run("add", "-A")
+
+	plan := []SplitGroup{
+		{Order: 1, Type: "refactor", Message: "rename old", Files: []string{"new.txt"}},
+		{Order: 2, Type: "feat", Message: "add b", Files: []string{"b.txt"}},
+	}
+	sr, err := StartSplit(plan, "", false, false)
+	if err != nil {
+		t.Fatalf("StartSplit: %v", err)
+	}
+	if err := sr.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := r.run("show", "--format=", "-M", "--name-status", "HEAD~1"); got != "R100\told.txt\tnew.txt" {
+		t.Errorf("rename commit =\n%s", got)
+	}
+	if got := r.run("status", "--porcelain"); got != "" {
+		t.Errorf("left behind after the split:\n%s", got)
+	}
+}
@@ -0,0 +1,77 @@
+package commit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dinoDanic/diny/git"
+	"gopkg.in/yaml.v3"
+)
+
+// State diny keeps per repository lives under <gitdir>/diny, out of the
+// worktree.
+
+func statePath(name string) (string, error) {
+	gitDir, err := git.FindGitDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, "diny", name), nil
+}
+
+// readState decodes the named state file into v, reporting false if it
+// does not exist.
+func readState(name string, v any) (bool, error) {
+	path, err := statePath(name)
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// writeState replaces the named state file atomically.
+func writeState(name string, v any) error {
+	path, err := statePath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to rename %s: %w", name, err)
+	}
+	return nil
+}
+
+func removeState(name string) error {
+	path, err := statePath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
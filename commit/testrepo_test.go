@@ -0,0 +1,50 @@
+package commit
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRepo is a scratch repository the test has changed into; its helpers
+// fail the test on error.
+type testRepo struct {
+	dir string
+	t   *testing.T
+}
+
+// newTestRepo initialises a repository on main in a temp dir with a
+// committer identity and changes into it, skipping the test when git is
+// not installed.
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	r := &testRepo{dir: t.TempDir(), t: t}
+	t.Chdir(r.dir)
+	r.run("init", "-q", "-b", "main")
+	r.run("config", "user.email", "t@example.com")
+	r.run("config", "user.name", "t")
+	return r
+}
+
+// run runs git and returns its trimmed combined output.
+func (r *testRepo) run(args ...string) string {
+	r.t.Helper()
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// write writes a file in the worktree.
+func (r *testRepo) write(name, content string) {
+	r.t.Helper()
+	if err := os.WriteFile(filepath.Join(r.dir, name), []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+}
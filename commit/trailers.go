@@ -0,0 +1,69 @@
+package commit
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/git"
+)
+
+// Trailers are kept apart from the generated message so they never reach
+// the backend and survive regenerating it; they are added when the message
+// leaves diny, by commit, draft or clipboard.
+
+// ConfigTrailers returns the trailers config adds to every commit: the
+// commit.trailers static ones, Signed-off-by for the git identity when
+// signoff is on, then the branch's issue key when commit.issue places it
+// in a trailer.
+func ConfigTrailers(cfg *config.Config) []string {
+	if cfg == nil {
+		return nil
+	}
+	trailers := append([]string(nil), cfg.Commit.Trailers.Static...)
+	if cfg.Commit.Trailers.Signoff {
+		if name, email := git.GetGitName(), git.GetGitEmail(); name != "" && email != "" {
+			trailers = append(trailers, "Signed-off-by: "+name+" <"+email+">")
+		}
+	}
+	if t := IssueTrailer(cfg.Commit.Issue, BranchIssueKey(cfg)); t != "" {
+		trailers = append(trailers, t)
+	}
+	return trailers
+}
+
+// CoAuthorTrailer is the trailer for a co_authors roster entry.
+func CoAuthorTrailer(author string) string {
+	return "Co-authored-by: " + author
+}
+
+// WithTrailers returns message with trailers appended.
+func WithTrailers(message string, trailers []string) (string, error) {
+	return git.AddTrailers(message, trailers)
+}
+
+var trailerLine = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*: \S`)
+
+// SplitTrailers separates a trailer block, the last paragraph when every
+// line of it is a trailer, from the rest of message. Used after the user
+// edits the message with its trailers shown, so both can change.
+func SplitTrailers(message string) (string, []string) {
+	message = strings.TrimSpace(message)
+	i := strings.LastIndex(message, "\n\n")
+	if i < 0 {
+		return message, nil
+	}
+	var trailers []string
+	for _, line := range strings.Split(message[i+2:], "\n") {
+		switch {
+		case trailerLine.MatchString(line):
+			trailers = append(trailers, strings.TrimSpace(line))
+		case (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(trailers) > 0:
+			// A folded continuation of the previous trailer.
+			trailers[len(trailers)-1] += " " + strings.TrimSpace(line)
+		default:
+			return message, nil
+		}
+	}
+	return strings.TrimSpace(message[:i]), trailers
+}
@@ -0,0 +1,40 @@
+package commit
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitTrailers(t *testing.T) {
+	tests := []struct {
+		message  string
+		body     string
+		trailers []string
+	}{
+		{"feat: add x", "feat: add x", nil},
+		{"feat: add x\n\nmore detail", "feat: add x\n\nmore detail", nil},
+		{"feat: add x\n\nbody\n\nSigned-off-by: A <a@x>\nCo-authored-by: B <b@x>",
+			"feat: add x\n\nbody", []string{"Signed-off-by: A <a@x>", "Co-authored-by: B <b@x>"}},
+		{"feat: add x\n\nRefs: 1\n  continued", "feat: add x", []string{"Refs: 1 continued"}},
+		{"feat: add x\n\nNote: this\nis prose", "feat: add x\n\nNote: this\nis prose", nil},
+	}
+	for _, tt := range tests {
+		body, trailers := SplitTrailers(tt.message)
+		if body != tt.body || !slices.Equal(trailers, tt.trailers) {
+			t.Errorf("SplitTrailers(%q) = %q, %q; want %q, %q", tt.message, body, trailers, tt.body, tt.trailers)
+		}
+	}
+}
+
+func TestWithTrailers(t *testing.T) {
+	newTestRepo(t)
+
+	got, err := WithTrailers("fix: y\n\nSigned-off-by: A <a@x>", []string{"Signed-off-by: A <a@x>", CoAuthorTrailer("B <b@x>")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "fix: y\n\nSigned-off-by: A <a@x>\nCo-authored-by: B <b@x>"
+	if got != want {
+		t.Errorf("WithTrailers = %q, want %q", got, want)
+	}
+}
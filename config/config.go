@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/dinoDanic/diny/git"
@@ -30,14 +31,133 @@ const (
 	Long   Length = "long"
 )
 
+// IssuePlacement is where commit.issue puts the issue key.
+type IssuePlacement string
+
+const (
+	IssuePrefix  IssuePlacement = "prefix"  // "ABC-123 fix: ..."
+	IssueScope   IssuePlacement = "scope"   // "fix(ABC-123): ..."
+	IssueTrailer IssuePlacement = "trailer" // "Refs: ABC-123"
+)
+
+type ProviderName string
+
+const (
+	ProviderDiny   ProviderName = "diny"
+	ProviderOllama ProviderName = "ollama"
+	ProviderOpenAI ProviderName = "openai"
+)
+
 type PromptsConfig struct {
 	Enabled bool `yaml:"enabled" json:"Enabled"`
 }
 
+// ProviderConfig selects the backend that generates messages. It is never
+// sent to the diny server.
+type ProviderConfig struct {
+	Name   ProviderName `yaml:"name"`
+	Ollama OllamaConfig `yaml:"ollama"`
+	OpenAI OpenAIConfig `yaml:"openai"`
+}
+
+// Identity names the backend and model answering requests, for keying
+// cached responses. It is empty for the hosted service.
+func (p ProviderConfig) Identity() string {
+	switch p.Name {
+	case ProviderOllama:
+		return string(p.Name) + "|" + p.Ollama.Endpoint + "|" + p.Ollama.Model + "|" + strconv.FormatFloat(p.Ollama.Temperature, 'g', -1, 64)
+	case ProviderOpenAI:
+		return string(p.Name) + "|" + p.OpenAI.BaseURL + "|" + p.OpenAI.Model + "|" + strconv.FormatFloat(p.OpenAI.Temperature, 'g', -1, 64)
+	}
+	return string(p.Name)
+}
+
+// OllamaConfig configures a local Ollama server. Diffs never leave the
+// machine (or LAN) when this provider is selected.
+type OllamaConfig struct {
+	Endpoint    string  `yaml:"endpoint"`
+	Model       string  `yaml:"model"`
+	Temperature float64 `yaml:"temperature"`
+	ContextSize int     `yaml:"context_size"`
+	KeepAlive   string  `yaml:"keep_alive"`
+}
+
+// OpenAIConfig configures any server speaking the OpenAI chat-completions
+// protocol. The API key itself is read from the environment variable named
+// by APIKeyEnv so it never lands in a config file.
+type OpenAIConfig struct {
+	BaseURL     string  `yaml:"base_url"`
+	Model       string  `yaml:"model"`
+	APIKeyEnv   string  `yaml:"api_key_env"`
+	Temperature float64 `yaml:"temperature"`
+}
+
+// CacheConfig controls the on-disk response cache under <gitdir>/diny/cache.
+type CacheConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	TTL       string `yaml:"ttl"`
+	MaxSizeMB int    `yaml:"max_size_mb"`
+}
+
+// LargeDiffConfig controls diffs that do not fit in one request. Above
+// Budget characters the diff is summarised chunk by chunk and the final
+// answer is written from the summaries. 0 always sends the diff whole.
+type LargeDiffConfig struct {
+	Budget int `yaml:"budget"`
+}
+
+// DiffConfig chooses which changed files have their contents sent. Both
+// lists use gitignore syntax; Include wins over Exclude, .dinyignore and
+// .gitattributes. Left-out files are still named in the diff.
+type DiffConfig struct {
+	Exclude []string `yaml:"exclude"`
+	Include []string `yaml:"include"`
+}
+
+// ServerConfig points the diny provider at a self-hosted `diny serve`
+// instance instead of the hosted service. DINY_SERVER_URL overrides it.
+type ServerConfig struct {
+	URL string `yaml:"url"`
+}
+
+// IdentityMode says how an identity field is sent: as is, as a SHA-256
+// hash, or not at all.
+type IdentityMode string
+
+const (
+	IdentitySend IdentityMode = "send"
+	IdentityHash IdentityMode = "hash"
+	IdentityOmit IdentityMode = "omit"
+)
+
+// PrivacyConfig controls the identity fields attached to requests to the
+// hosted diny service and to feedback. An empty mode means send.
+type PrivacyConfig struct {
+	Name     IdentityMode `yaml:"name"`
+	Email    IdentityMode `yaml:"email"`
+	RepoName IdentityMode `yaml:"repo_name"`
+	System   IdentityMode `yaml:"system"`
+}
+
+// RedactConfig controls the secret scrubbing applied to diffs before they
+// are sent to any backend. Patterns are extra regular expressions; every
+// match is replaced with a placeholder.
+type RedactConfig struct {
+	Enabled  bool     `yaml:"enabled"`
+	Patterns []string `yaml:"patterns"`
+}
+
 type Config struct {
-	Theme   string        `yaml:"theme" json:"Theme"`
-	Commit  CommitConfig  `yaml:"commit" json:"Request"`
-	Prompts PromptsConfig `yaml:"prompts" json:"Prompts"`
+	Theme     string          `yaml:"theme" json:"Theme"`
+	Commit    CommitConfig    `yaml:"commit" json:"Request"`
+	Prompts   PromptsConfig   `yaml:"prompts" json:"Prompts"`
+	Provider  ProviderConfig  `yaml:"provider" json:"-"`
+	Cache     CacheConfig     `yaml:"cache" json:"-"`
+	Redact    RedactConfig    `yaml:"redact" json:"-"`
+	Privacy   PrivacyConfig   `yaml:"privacy" json:"-"`
+	Server    ServerConfig    `yaml:"server" json:"-"`
+	LargeDiff LargeDiffConfig `yaml:"large_diff" json:"-"`
+	Diff      DiffConfig      `yaml:"diff" json:"-"`
 }
 
 type CommitConfig struct {
@@ -47,16 +167,93 @@ type CommitConfig struct {
 	Length             Length `yaml:"length" json:"Length"`
 	CustomInstructions string `yaml:"custom_instructions" json:"CustomInstructions"`
 	HashAfterCommit    bool   `yaml:"hash_after_commit" json:"HashAfterCommit"`
+	// Trailers are added locally after generation, never sent.
+	Trailers TrailersConfig `yaml:"trailers" json:"-"`
+	// Issue is applied locally to every generated message, never sent.
+	Issue IssueConfig `yaml:"issue" json:"-"`
+}
+
+type TrailersConfig struct {
+	Static    []string `yaml:"static"`
+	Signoff   bool     `yaml:"signoff"`
+	CoAuthors []string `yaml:"co_authors"`
+}
+
+// IssueConfig pulls an issue key out of the branch name with Pattern (the
+// first capture group, or the whole match) and adds it at Placement. An
+// empty Pattern turns it off.
+type IssueConfig struct {
+	Pattern   string         `yaml:"pattern"`
+	Placement IssuePlacement `yaml:"placement"`
 }
 
 type LocalPromptsConfig struct {
 	Enabled *bool `yaml:"enabled,omitempty"`
 }
 
+type LocalProviderConfig struct {
+	Name   ProviderName      `yaml:"name,omitempty"`
+	Ollama LocalOllamaConfig `yaml:"ollama,omitempty"`
+	OpenAI LocalOpenAIConfig `yaml:"openai,omitempty"`
+}
+
+type LocalOllamaConfig struct {
+	Endpoint    string   `yaml:"endpoint,omitempty"`
+	Model       string   `yaml:"model,omitempty"`
+	Temperature *float64 `yaml:"temperature,omitempty"`
+	ContextSize int      `yaml:"context_size,omitempty"`
+	KeepAlive   string   `yaml:"keep_alive,omitempty"`
+}
+
+type LocalOpenAIConfig struct {
+	BaseURL     string   `yaml:"base_url,omitempty"`
+	Model       string   `yaml:"model,omitempty"`
+	APIKeyEnv   string   `yaml:"api_key_env,omitempty"`
+	Temperature *float64 `yaml:"temperature,omitempty"`
+}
+
+type LocalCacheConfig struct {
+	Enabled   *bool  `yaml:"enabled,omitempty"`
+	TTL       string `yaml:"ttl,omitempty"`
+	MaxSizeMB int    `yaml:"max_size_mb,omitempty"`
+}
+
+type LocalRedactConfig struct {
+	Enabled  *bool    `yaml:"enabled,omitempty"`
+	Patterns []string `yaml:"patterns,omitempty"`
+}
+
+type LocalPrivacyConfig struct {
+	Name     IdentityMode `yaml:"name,omitempty"`
+	Email    IdentityMode `yaml:"email,omitempty"`
+	RepoName IdentityMode `yaml:"repo_name,omitempty"`
+	System   IdentityMode `yaml:"system,omitempty"`
+}
+
+type LocalServerConfig struct {
+	URL string `yaml:"url,omitempty"`
+}
+
+type LocalLargeDiffConfig struct {
+	Budget *int `yaml:"budget,omitempty"`
+}
+
+type LocalDiffConfig struct {
+	Exclude []string `yaml:"exclude,omitempty"`
+	Include []string `yaml:"include,omitempty"`
+}
+
 type LocalConfig struct {
-	Theme   string             `yaml:"theme,omitempty"`
-	Commit  LocalCommitConfig  `yaml:"commit,omitempty"`
-	Prompts LocalPromptsConfig `yaml:"prompts,omitempty"`
+	Theme     string               `yaml:"theme,omitempty"`
+	Commit    LocalCommitConfig    `yaml:"commit,omitempty"`
+	Prompts   LocalPromptsConfig   `yaml:"prompts,omitempty"`
+	Provider  LocalProviderConfig  `yaml:"provider,omitempty"`
+	Cache     LocalCacheConfig     `yaml:"cache,omitempty"`
+	Redact    LocalRedactConfig    `yaml:"redact,omitempty"`
+	Privacy   LocalPrivacyConfig   `yaml:"privacy,omitempty"`
+	Server    LocalServerConfig    `yaml:"server,omitempty"`
+	LargeDiff LocalLargeDiffConfig `yaml:"large_diff,omitempty"`
+	Diff      LocalDiffConfig      `yaml:"diff,omitempty"`
 }
 
 type LocalCommitConfig struct {
@@ -66,6 +263,20 @@ type LocalCommitConfig struct {
 	Length             Length `yaml:"length,omitempty"`
 	CustomInstructions string `yaml:"custom_instructions,omitempty"`
 	HashAfterCommit    *bool  `yaml:"hash_after_commit,omitempty"`
+
+	Trailers LocalTrailersConfig `yaml:"trailers,omitempty"`
+	Issue    LocalIssueConfig    `yaml:"issue,omitempty"`
+}
+
+type LocalTrailersConfig struct {
+	Static    []string `yaml:"static,omitempty"`
+	Signoff   *bool    `yaml:"signoff,omitempty"`
+	CoAuthors []string `yaml:"co_authors,omitempty"`
+}
+
+type LocalIssueConfig struct {
+	Pattern   string         `yaml:"pattern,omitempty"`
+	Placement IssuePlacement `yaml:"placement,omitempty"`
 }
 
 func loadDefaultConfig() (*Config, error) {
@@ -241,10 +452,19 @@ func mergeConfigWithLocal(base *Config, overlay *LocalConfig) *Config {
 			Length:             base.Commit.Length,
 			CustomInstructions: base.Commit.CustomInstructions,
 			HashAfterCommit:    base.Commit.HashAfterCommit,
+			Trailers:           base.Commit.Trailers,
+			Issue:              base.Commit.Issue,
 		},
 		Prompts: PromptsConfig{
 			Enabled: base.Prompts.Enabled,
 		},
+		Provider:  base.Provider,
+		Cache:     base.Cache,
+		Redact:    base.Redact,
+		Privacy:   base.Privacy,
+		Server:    base.Server,
+		LargeDiff: base.LargeDiff,
+		Diff:      base.Diff,
 	}
 
 	if overlay.Theme != "" {
@@ -268,9 +488,93 @@ func mergeConfigWithLocal(base *Config, overlay *LocalConfig) *Config {
 	if overlay.Commit.CustomInstructions != "" {
 		merged.Commit.CustomInstructions = overlay.Commit.CustomInstructions
 	}
+	if overlay.Commit.Trailers.Static != nil {
+		merged.Commit.Trailers.Static = overlay.Commit.Trailers.Static
+	}
+	if overlay.Commit.Trailers.Signoff != nil {
+		merged.Commit.Trailers.Signoff = *overlay.Commit.Trailers.Signoff
+	}
+	if overlay.Commit.Trailers.CoAuthors != nil {
+		merged.Commit.Trailers.CoAuthors = overlay.Commit.Trailers.CoAuthors
+	}
+	if overlay.Commit.Issue.Pattern != "" {
+		merged.Commit.Issue.Pattern = overlay.Commit.Issue.Pattern
+	}
+	if overlay.Commit.Issue.Placement != "" {
+		merged.Commit.Issue.Placement = overlay.Commit.Issue.Placement
+	}
 	if overlay.Prompts.Enabled != nil {
 		merged.Prompts.Enabled = *overlay.Prompts.Enabled
 	}
+	if overlay.Provider.Name != "" {
+		merged.Provider.Name = overlay.Provider.Name
+	}
+	if overlay.Provider.Ollama.Endpoint != "" {
+		merged.Provider.Ollama.Endpoint = overlay.Provider.Ollama.Endpoint
+	}
+	if overlay.Provider.Ollama.Model != "" {
+		merged.Provider.Ollama.Model = overlay.Provider.Ollama.Model
+	}
+	if overlay.Provider.Ollama.Temperature != nil {
+		merged.Provider.Ollama.Temperature = *overlay.Provider.Ollama.Temperature
+	}
+	if overlay.Provider.Ollama.ContextSize != 0 {
+		merged.Provider.Ollama.ContextSize = overlay.Provider.Ollama.ContextSize
+	}
+	if overlay.Provider.Ollama.KeepAlive != "" {
+		merged.Provider.Ollama.KeepAlive = overlay.Provider.Ollama.KeepAlive
+	}
+	if overlay.Provider.OpenAI.BaseURL != "" {
+		merged.Provider.OpenAI.BaseURL = overlay.Provider.OpenAI.BaseURL
+	}
+	if overlay.Provider.OpenAI.Model != "" {
+		merged.Provider.OpenAI.Model = overlay.Provider.OpenAI.Model
+	}
+	if overlay.Provider.OpenAI.APIKeyEnv != "" {
+		merged.Provider.OpenAI.APIKeyEnv = overlay.Provider.OpenAI.APIKeyEnv
+	}
+	if overlay.Provider.OpenAI.Temperature != nil {
+		merged.Provider.OpenAI.Temperature = *overlay.Provider.OpenAI.Temperature
+	}
+	if overlay.Cache.Enabled != nil {
+		merged.Cache.Enabled = *overlay.Cache.Enabled
+	}
+	if overlay.Cache.TTL != "" {
+		merged.Cache.TTL = overlay.Cache.TTL
+	}
+	if overlay.Cache.MaxSizeMB != 0 {
+		merged.Cache.MaxSizeMB = overlay.Cache.MaxSizeMB
+	}
+	if overlay.Redact.Enabled != nil {
+		merged.Redact.Enabled = *overlay.Redact.Enabled
+	}
+	if len(overlay.Redact.Patterns) > 0 {
+		merged.Redact.Patterns = overlay.Redact.Patterns
+	}
+	if overlay.Privacy.Name != "" {
+		merged.Privacy.Name = overlay.Privacy.Name
+	}
+	if overlay.Privacy.Email != "" {
+		merged.Privacy.Email = overlay.Privacy.Email
+	}
+	if overlay.Privacy.RepoName != "" {
+		merged.Privacy.RepoName = overlay.Privacy.RepoName
+	}
+	if overlay.Privacy.System != "" {
+		merged.Privacy.System = overlay.Privacy.System
+	}
+	if overlay.Server.URL != "" {
+		merged.Server.URL = overlay.Server.URL
+	}
+	if overlay.LargeDiff.Budget != nil {
+		merged.LargeDiff.Budget = *overlay.LargeDiff.Budget
+	}
+	if overlay.Diff.Exclude != nil {
+		merged.Diff.Exclude = overlay.Diff.Exclude
+	}
+	if overlay.Diff.Include != nil {
+		merged.Diff.Include = overlay.Diff.Include
+	}
 
 	return merged
 }
@@ -396,6 +700,20 @@ func createVersionedProjectConfigIfNeeded() error {
 #   length: short
 #   custom_instructions: ""
 #   hash_after_commit: false
+#   trailers:
+#     signoff: true
+#     co_authors:
+#       - "Jane Doe <jane@example.com>"
+#   issue:
+#     pattern: '([A-Z]+-\d+)'
+#     placement: prefix
+
+# Generation backend (diny, ollama, openai)
+# provider:
+#   name: ollama
+#   ollama:
+#     endpoint: http://127.0.0.1:11434
+#     model: llama3.2
 `
 
 	if err := os.WriteFile(path, []byte(template), 0644); err != nil {
@@ -438,6 +756,13 @@ func createLocalProjectConfigIfNeeded() error {
 #   length: short
 #   custom_instructions: ""
 #   hash_after_commit: false
+
+# Generation backend (diny, ollama, openai)
+# provider:
+#   name: ollama
+#   ollama:
+#     endpoint: http://127.0.0.1:11434
+#     model: llama3.2
 `
 
 	if err := os.WriteFile(path, []byte(template), 0644); err != nil {
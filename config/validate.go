@@ -2,7 +2,21 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
 	"slices"
+	"time"
+
+	"github.com/dinoDanic/diny/git"
+)
+
+// minLargeDiffBudget keeps chunks big enough to carry a file header and
+// some context.
+const minLargeDiffBudget = 2000
+
+var (
+	trailerPattern  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*: \S.*$`)
+	coAuthorPattern = regexp.MustCompile(`^[^<>]+ <[^<>\s]+@[^<>\s]+>$`)
 )
 
 func (c *Config) Validate() error {
@@ -26,5 +40,110 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid length '%s', must be one of: short, normal, long", c.Commit.Length)
 	}
 
+	validProviders := []ProviderName{ProviderDiny, ProviderOllama, ProviderOpenAI}
+	if c.Provider.Name != "" && !slices.Contains(validProviders, c.Provider.Name) {
+		return fmt.Errorf("invalid provider '%s', must be one of: diny, ollama, openai", c.Provider.Name)
+	}
+
+	if c.Provider.Name == ProviderOllama {
+		if c.Provider.Ollama.Endpoint == "" {
+			return fmt.Errorf("provider.ollama.endpoint is required when provider is ollama")
+		}
+		if c.Provider.Ollama.Model == "" {
+			return fmt.Errorf("provider.ollama.model is required when provider is ollama")
+		}
+		if c.Provider.Ollama.Temperature < 0 || c.Provider.Ollama.Temperature > 2 {
+			return fmt.Errorf("invalid provider.ollama.temperature %v, must be between 0 and 2", c.Provider.Ollama.Temperature)
+		}
+		if c.Provider.Ollama.ContextSize < 0 {
+			return fmt.Errorf("invalid provider.ollama.context_size %d, must not be negative", c.Provider.Ollama.ContextSize)
+		}
+	}
+
+	if c.Provider.Name == ProviderOpenAI {
+		if c.Provider.OpenAI.BaseURL == "" {
+			return fmt.Errorf("provider.openai.base_url is required when provider is openai")
+		}
+		if c.Provider.OpenAI.Model == "" {
+			return fmt.Errorf("provider.openai.model is required when provider is openai")
+		}
+		if c.Provider.OpenAI.Temperature < 0 || c.Provider.OpenAI.Temperature > 2 {
+			return fmt.Errorf("invalid provider.openai.temperature %v, must be between 0 and 2", c.Provider.OpenAI.Temperature)
+		}
+	}
+
+	if c.Cache.TTL != "" {
+		if ttl, err := time.ParseDuration(c.Cache.TTL); err != nil || ttl < 0 {
+			return fmt.Errorf("invalid cache.ttl '%s', must be a duration like 24h or 30m", c.Cache.TTL)
+		}
+	}
+	if c.Cache.MaxSizeMB < 0 {
+		return fmt.Errorf("invalid cache.max_size_mb %d, must not be negative", c.Cache.MaxSizeMB)
+	}
+
+	if c.Server.URL != "" {
+		if u, err := url.Parse(c.Server.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid server.url '%s', must be an http or https URL", c.Server.URL)
+		}
+	}
+
+	if c.LargeDiff.Budget != 0 && c.LargeDiff.Budget < minLargeDiffBudget {
+		return fmt.Errorf("invalid large_diff.budget %d, must be 0 or at least %d", c.LargeDiff.Budget, minLargeDiffBudget)
+	}
+
+	validModes := []IdentityMode{IdentitySend, IdentityHash, IdentityOmit}
+	privacyFields := []struct {
+		name string
+		mode IdentityMode
+	}{
+		{"name", c.Privacy.Name},
+		{"email", c.Privacy.Email},
+		{"repo_name", c.Privacy.RepoName},
+		{"system", c.Privacy.System},
+	}
+	for _, f := range privacyFields {
+		if f.mode != "" && !slices.Contains(validModes, f.mode) {
+			return fmt.Errorf("invalid privacy.%s '%s', must be one of: send, hash, omit", f.name, f.mode)
+		}
+	}
+
+	for _, p := range c.Redact.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid redact.patterns entry '%s': %v", p, err)
+		}
+	}
+
+	for _, t := range c.Commit.Trailers.Static {
+		if !trailerPattern.MatchString(t) {
+			return fmt.Errorf("invalid commit.trailers.static entry '%s', must look like 'Key: value'", t)
+		}
+	}
+	for _, a := range c.Commit.Trailers.CoAuthors {
+		if !coAuthorPattern.MatchString(a) {
+			return fmt.Errorf("invalid commit.trailers.co_authors entry '%s', must look like 'Name <email>'", a)
+		}
+	}
+
+	if c.Commit.Issue.Pattern != "" {
+		if _, err := regexp.Compile(c.Commit.Issue.Pattern); err != nil {
+			return fmt.Errorf("invalid commit.issue.pattern '%s': %v", c.Commit.Issue.Pattern, err)
+		}
+	}
+	validPlacements := []IssuePlacement{IssuePrefix, IssueScope, IssueTrailer}
+	if c.Commit.Issue.Placement != "" && !slices.Contains(validPlacements, c.Commit.Issue.Placement) {
+		return fmt.Errorf("invalid commit.issue.placement '%s', must be one of: prefix, scope, trailer", c.Commit.Issue.Placement)
+	}
+
+	for _, list := range []struct {
+		name     string
+		patterns []string
+	}{{"exclude", c.Diff.Exclude}, {"include", c.Diff.Include}} {
+		for _, p := range list.patterns {
+			if err := git.CheckPattern(p); err != nil {
+				return fmt.Errorf("invalid diff.%s entry '%s': %v", list.name, p, err)
+			}
+		}
+	}
+
 	return nil
 }
@@ -7,22 +7,45 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/privacy"
 	"github.com/dinoDanic/diny/server"
+	"github.com/dinoDanic/diny/version"
 )
 
 type Payload struct {
 	Type     string `json:"type"`
 	Value    string `json:"value"`
-	Email    string `json:"email"`
-	Name     string `json:"name"`
+	Email    string `json:"email,omitempty"`
+	Name     string `json:"name,omitempty"`
 	Version  string `json:"version"`
-	System   string `json:"system"`
-	RepoName string `json:"repoName"`
+	System   string `json:"system,omitempty"`
+	RepoName string `json:"repoName,omitempty"`
+}
+
+// NewPayload builds a feedback payload with identity fields filtered by the
+// privacy config.
+func NewPayload(kind, value string, cfg *config.Config) Payload {
+	id := privacy.Collect(cfg.Privacy)
+	return Payload{
+		Type:     kind,
+		Value:    value,
+		Email:    id.Email,
+		Name:     id.Name,
+		Version:  version.Get(),
+		System:   id.System,
+		RepoName: id.RepoName,
+	}
 }
 
 // Send posts feedback to the backend. Failures are silently swallowed —
-// a flaky network must never disrupt the commit flow.
+// a flaky network must never disrupt the commit flow. Nothing is sent when
+// DO_NOT_TRACK or DINY_NO_TELEMETRY is set.
 func Send(p Payload) {
+	if off, _ := privacy.TelemetryDisabled(); off {
+		return
+	}
+
 	buf, err := json.Marshal(p)
 	if err != nil {
 		return
@@ -1,8 +1,6 @@
 package git
 
-import "os/exec"
-
 func AddAll() error {
-	cmd := exec.Command("git", "add", "-A")
+	cmd := cwd.Command("add", "-A")
 	return cmd.Run()
 }
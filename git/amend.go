@@ -0,0 +1,100 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// GetAmendDiff is the diff an amend of HEAD would commit: from HEAD's
+// parent to the index, so HEAD's own changes and the staged ones together.
+func GetAmendDiff() (string, error) {
+	return cwd.AmendDiff()
+}
+
+func (r *Repo) AmendDiff() (string, error) {
+	base, err := r.amendBase()
+	if err != nil {
+		return "", err
+	}
+	diff, err := r.diffWithExcludes("--cached", base)
+	if err != nil {
+		return "", fmt.Errorf("failed to get amend diff: %w", err)
+	}
+	return diff, nil
+}
+
+// amendBase is HEAD's parent, or the empty tree when HEAD is a root commit.
+func (r *Repo) amendBase() (string, error) {
+	if _, err := r.Head(); err != nil {
+		return "", fmt.Errorf("nothing to amend: there are no commits yet")
+	}
+	return r.parentOrEmptyTree("HEAD")
+}
+
+// parentOrEmptyTree is rev's first parent, or the empty tree for a root
+// commit, so diffing against it shows everything rev introduced.
+func (r *Repo) parentOrEmptyTree(rev string) (string, error) {
+	if out, err := r.Command("rev-parse", "--verify", "--quiet", rev+"~1").Output(); err == nil {
+		return strings.TrimSpace(string(out)), nil
+	}
+	cmd := r.Command("hash-object", "-t", "tree", "--stdin")
+	cmd.Stdin = strings.NewReader("")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the empty tree: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetHeadMessage returns the full message of HEAD.
+func GetHeadMessage() (string, error) {
+	return cwd.HeadMessage()
+}
+
+func (r *Repo) HeadMessage() (string, error) {
+	out, err := r.Command("log", "-1", "--format=%B", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the HEAD message: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetHeadUpstream returns the current branch's upstream if HEAD is already
+// on it, i.e. has been pushed, and "" otherwise.
+func GetHeadUpstream() (string, error) {
+	return cwd.HeadUpstream()
+}
+
+func (r *Repo) HeadUpstream() (string, error) {
+	out, err := r.Command("rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}").Output()
+	if err != nil {
+		// No upstream configured.
+		return "", nil
+	}
+	upstream := strings.TrimSpace(string(out))
+	onUpstream, err := r.IsAncestor("HEAD", upstream)
+	if err != nil || !onUpstream {
+		return "", err
+	}
+	return upstream, nil
+}
+
+// IsAncestor reports whether commit a is reachable from b.
+func IsAncestor(a, b string) (bool, error) {
+	return cwd.IsAncestor(a, b)
+}
+
+func (r *Repo) IsAncestor(a, b string) (bool, error) {
+	err := r.Command("merge-base", "--is-ancestor", a, b).Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to compare %s with %s: %w", a, b, err)
+	}
+}
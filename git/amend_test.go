@@ -0,0 +1,53 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAmendDiffAndUpstream(t *testing.T) {
+	r := newTestRepo(t)
+	if _, err := r.AmendDiff(); err == nil {
+		t.Errorf("AmendDiff with no commits should fail")
+	}
+
+	r.write("a.txt", "one\n")
+	r.run("add", ".")
+	r.run("commit", "-qm", "root")
+	// A root commit is diffed against the empty tree.
+	if diff, err := r.AmendDiff(); err != nil || !strings.Contains(diff, "+one") {
+		t.Errorf("AmendDiff on root = %q, %v", diff, err)
+	}
+
+	r.write("b.txt", "head\n")
+	r.run("add", ".")
+	r.run("commit", "-qm", "add b\n\nwith a body")
+	r.write("c.txt", "staged\n")
+	r.run("add", "c.txt")
+
+	diff, err := r.AmendDiff()
+	if err != nil {
+		t.Fatalf("AmendDiff: %v", err)
+	}
+	if !strings.Contains(diff, "+head") || !strings.Contains(diff, "+staged") || strings.Contains(diff, "+one") {
+		t.Errorf("AmendDiff should cover HEAD and the index only:\n%s", diff)
+	}
+	if msg, err := r.HeadMessage(); err != nil || msg != "add b\n\nwith a body" {
+		t.Errorf("HeadMessage = %q, %v", msg, err)
+	}
+
+	if up, err := r.HeadUpstream(); err != nil || up != "" {
+		t.Errorf("HeadUpstream without upstream = %q, %v", up, err)
+	}
+	remote := t.TempDir()
+	r.run("init", "-q", "--bare", remote)
+	r.run("remote", "add", "origin", remote)
+	r.run("push", "-q", "-u", "origin", "HEAD:main")
+	if up, err := r.HeadUpstream(); err != nil || up != "origin/main" {
+		t.Errorf("HeadUpstream after push = %q, %v", up, err)
+	}
+	r.run("commit", "-qm", "local only")
+	if up, err := r.HeadUpstream(); err != nil || up != "" {
+		t.Errorf("HeadUpstream for an unpushed HEAD = %q, %v", up, err)
+	}
+}
@@ -5,7 +5,6 @@ package git
 
 import (
 	"fmt"
-	"os/exec"
 	"strings"
 	"time"
 )
@@ -27,7 +26,7 @@ func GetCommitsByDateRange(startDate, endDate string) ([]string, error) {
 		return nil, fmt.Errorf("failed to get git user name")
 	}
 
-	cmd := exec.Command("git", "log",
+	cmd := cwd.Command("log",
 		"--since="+startDate,
 		"--until="+endDate,
 		"--author="+authorName,
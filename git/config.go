@@ -5,13 +5,12 @@ package git
 
 import (
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 )
 
 func GetGitName() string {
-	cmd := exec.Command("git", "config", "user.name")
+	cmd := cwd.Command("config", "user.name")
 	output, err := cmd.Output()
 	if err != nil {
 		return ""
@@ -20,7 +19,7 @@ func GetGitName() string {
 }
 
 func GetGitEmail() string {
-	cmd := exec.Command("git", "config", "user.email")
+	cmd := cwd.Command("config", "user.email")
 	output, err := cmd.Output()
 	if err != nil {
 		return ""
@@ -29,14 +28,14 @@ func GetGitEmail() string {
 }
 
 func GetRepoName() string {
-	cmd := exec.Command("git", "config", "--get", "remote.origin.url")
+	cmd := cwd.Command("config", "--get", "remote.origin.url")
 	output, err := cmd.Output()
 	if err != nil {
-		cwd, cwdErr := exec.Command("pwd").Output()
-		if cwdErr != nil {
+		root, rootErr := FindGitRoot()
+		if rootErr != nil {
 			return ""
 		}
-		return filepath.Base(strings.TrimSpace(string(cwd)))
+		return filepath.Base(root)
 	}
 
 	url := strings.TrimSpace(string(output))
@@ -59,7 +58,7 @@ func GetGitEditor() string {
 		return editor
 	}
 
-	cmd := exec.Command("git", "config", "--get", "core.editor")
+	cmd := cwd.Command("config", "--get", "core.editor")
 	if output, err := cmd.Output(); err == nil {
 		if editor := strings.TrimSpace(string(output)); editor != "" {
 			return editor
@@ -0,0 +1,273 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Files whose contents are left out of diffs sent to the model, in order of
+// precedence: diff.include always keeps a file; otherwise the last matching
+// diff.exclude or .dinyignore rule decides, and files marked
+// linguist-generated or -diff in .gitattributes are left out. Left-out files
+// still appear in the diff as a header and a one-line note, so the model
+// knows they changed.
+
+// IgnoreFile is read from the top of the worktree, in gitignore syntax.
+const IgnoreFile = ".dinyignore"
+
+var diffRules struct {
+	exclude, include []ignoreRule
+}
+
+// ConfigureDiff sets the diff.exclude and diff.include patterns, both in
+// gitignore syntax.
+func ConfigureDiff(exclude, include []string) {
+	diffRules.exclude = parseIgnore(exclude, "diff.exclude")
+	diffRules.include = parseIgnore(include, "diff.include")
+}
+
+// CheckPattern reports whether p is a usable gitignore pattern.
+func CheckPattern(p string) error {
+	_, err := compileIgnore(strings.TrimPrefix(p, "!"))
+	return err
+}
+
+// ExcludedFile is a changed file whose contents are left out of the diff.
+type ExcludedFile struct {
+	StagedFile
+	Reason string // the rule source: diff.exclude, .dinyignore, linguist-generated or -diff
+}
+
+func (r *Repo) excludedFiles(files []StagedFile) ([]ExcludedFile, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	rules := diffRules.exclude
+	if root, err := r.Root(); err == nil {
+		if data, err := os.ReadFile(filepath.Join(root, IgnoreFile)); err == nil {
+			rules = append(rules[:len(rules):len(rules)], parseIgnore(strings.Split(string(data), "\n"), IgnoreFile)...)
+		}
+	}
+
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+	attrs, err := r.diffAttributes(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var excluded []ExcludedFile
+	for _, f := range files {
+		if matchIgnore(diffRules.include, f.Path) != "" {
+			continue
+		}
+		reason := matchIgnore(rules, f.Path)
+		if reason == "" {
+			reason = attrs[f.Path]
+		}
+		if reason != "" {
+			excluded = append(excluded, ExcludedFile{StagedFile: f, Reason: reason})
+		}
+	}
+	return excluded, nil
+}
+
+// diffAttributes returns "linguist-generated" or "-diff" for the paths
+// .gitattributes marks that way.
+func (r *Repo) diffAttributes(paths []string) (map[string]string, error) {
+	cmd := r.Command("check-attr", "-z", "--stdin", "linguist-generated", "diff")
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00") + "\x00")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .gitattributes: %w", err)
+	}
+
+	attrs := map[string]string{}
+	fields := splitZ(out)
+	for i := 0; i+2 < len(fields); i += 3 {
+		path, name, value := fields[i], fields[i+1], fields[i+2]
+		switch {
+		case name == "linguist-generated" && (value == "set" || value == "true"):
+			attrs[path] = "linguist-generated"
+		case name == "diff" && value == "unset" && attrs[path] == "":
+			attrs[path] = "-diff"
+		}
+	}
+	return attrs, nil
+}
+
+// diffWithExcludes is `git diff revs...` as sent for generation, with
+// excluded files reduced to a note as in Diff.
+func (r *Repo) diffWithExcludes(revs ...string) (string, error) {
+	out, err := r.Command(append(append([]string{"diff"}, revs...), "--name-status", "-z")...).Output()
+	if err != nil {
+		return "", err
+	}
+	excluded, err := r.excludedFiles(parseNameStatus(out))
+	if err != nil {
+		return "", err
+	}
+	return r.filteredDiff(append(append([]string{"diff"}, revs...),
+		"-U3", "--no-color", "--ignore-all-space", "--ignore-blank-lines"), excluded)
+}
+
+// filteredDiff runs `git diff args...` without the contents of excluded
+// files and appends a stub section for each of them.
+func (r *Repo) filteredDiff(args []string, excluded []ExcludedFile) (string, error) {
+	if len(excluded) > 0 {
+		args = append(args, "--")
+		for _, f := range excluded {
+			args = append(args, ":(exclude,literal)"+f.Path)
+			if f.Status == "R" {
+				args = append(args, ":(exclude,literal)"+f.From)
+			}
+		}
+	}
+	out, err := r.Command(args...).Output()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.Write(out)
+	for _, f := range excluded {
+		from := f.Path
+		if f.From != "" {
+			from = f.From
+		}
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\n(%s, contents not shown: %s)\n", from, f.Path, statusWord(f.Status), f.Reason)
+	}
+	return b.String(), nil
+}
+
+func statusWord(status string) string {
+	switch status {
+	case "A":
+		return "added"
+	case "D":
+		return "deleted"
+	case "R":
+		return "renamed"
+	case "C":
+		return "copied"
+	case "T":
+		return "type changed"
+	default:
+		return "modified"
+	}
+}
+
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+	source  string
+}
+
+func parseIgnore(lines []string, source string) []ignoreRule {
+	var rules []ignoreRule
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{source: source}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		re, err := compileIgnore(line)
+		if err != nil {
+			continue
+		}
+		rule.re = re
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// compileIgnore turns a gitignore glob into a regexp over slash-separated
+// paths. Patterns without a slash match a name at any depth; the rest are
+// anchored at the top of the worktree.
+func compileIgnore(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(strings.TrimRight(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "/**") && i+3 == len(pattern):
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated [ in %q", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// matchIgnore applies rules to a file path and returns the source of the
+// rule that matches it, or "" if none does or the last match is negated. A
+// rule matching a directory covers everything below it, as in .gitignore.
+func matchIgnore(rules []ignoreRule, path string) string {
+	source := ""
+	parts := strings.Split(path, "/")
+	for _, rule := range rules {
+		for n := 1; n <= len(parts); n++ {
+			if n == len(parts) && rule.dirOnly {
+				break
+			}
+			if rule.re.MatchString(strings.Join(parts[:n], "/")) {
+				source = rule.source
+				if rule.negate {
+					source = ""
+				}
+				break
+			}
+		}
+	}
+	return source
+}
@@ -0,0 +1,42 @@
+package git
+
+import "testing"
+
+func TestMatchIgnore_GitignoreSyntax(t *testing.T) {
+	rules := parseIgnore([]string{
+		"# comment",
+		"*.lock",
+		"vendor/",
+		"/build",
+		"proto/**/*.pb.go",
+		"!keep.lock",
+	}, "diff.exclude")
+
+	cases := map[string]string{
+		"Cargo.lock":          "diff.exclude",
+		"web/yarn.lock":       "diff.exclude",
+		"keep.lock":           "",
+		"vendor/x/y.go":       "diff.exclude",
+		"vendor":              "",
+		"build/out.js":        "diff.exclude",
+		"web/build/out.js":    "",
+		"proto/a/b/svc.pb.go": "diff.exclude",
+		"proto/svc.pb.go":     "diff.exclude",
+		"internal/svc.pb.go":  "",
+		"src/main.go":         "",
+	}
+	for path, want := range cases {
+		if got := matchIgnore(rules, path); got != want {
+			t.Errorf("matchIgnore(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestCheckPattern_RejectsBrokenClass(t *testing.T) {
+	if err := CheckPattern("*.[ch"); err == nil {
+		t.Errorf("expected an error for an unterminated class")
+	}
+	if err := CheckPattern("!*.[ch]"); err != nil {
+		t.Errorf("CheckPattern: %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// GetHooksDir is the directory git runs hooks from, following
+// core.hooksPath when it is set.
+func GetHooksDir() (string, error) {
+	return cwd.HooksDir()
+}
+
+func (r *Repo) HooksDir() (string, error) {
+	out, err := r.Command("rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("not in a git repository")
+	}
+	dir := strings.TrimSuffix(string(out), "\n")
+	if !filepath.IsAbs(dir) {
+		// --git-path answers relative to where it ran.
+		dir = filepath.Join(r.Dir, dir)
+	}
+	return filepath.Abs(dir)
+}
@@ -0,0 +1,103 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WriteTree snapshots the index as a tree object and returns its hash.
+func WriteTree() (string, error) {
+	return cwd.WriteTree()
+}
+
+func (r *Repo) WriteTree() (string, error) {
+	out, err := r.Command("write-tree").Output()
+	if err != nil {
+		return "", fmt.Errorf("git write-tree failed: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ReadTree replaces the index with tree, leaving the worktree alone.
+func ReadTree(tree string) error {
+	return cwd.ReadTree(tree)
+}
+
+func (r *Repo) ReadTree(tree string) error {
+	if out, err := r.Command("read-tree", tree).CombinedOutput(); err != nil {
+		return fmt.Errorf("git read-tree failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// StageFrom stages paths exactly as they are in tree, including deletions,
+// without looking at the worktree.
+func StageFrom(tree string, paths []string) error {
+	return cwd.StageFrom(tree, paths)
+}
+
+func (r *Repo) StageFrom(tree string, paths []string) error {
+	args := []string{"restore", "--staged", "--source=" + tree, "--"}
+	for _, p := range paths {
+		args = append(args, ":(literal)"+p)
+	}
+	if out, err := r.Command(args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Reset resets the index to HEAD, leaving the worktree alone.
+func Reset() error {
+	return cwd.Reset()
+}
+
+func (r *Repo) Reset() error {
+	if out, err := r.Command("reset", "-q").CombinedOutput(); err != nil {
+		return fmt.Errorf("git reset failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// ResetSoft moves HEAD to commit, leaving the index and worktree alone.
+func ResetSoft(commit string) error {
+	return cwd.ResetSoft(commit)
+}
+
+func (r *Repo) ResetSoft(commit string) error {
+	if out, err := r.Command("reset", "--soft", commit).CombinedOutput(); err != nil {
+		return fmt.Errorf("git reset failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// ResetUnborn deletes the branch HEAD is on, taking it back to before its
+// first commit; the index and worktree are left alone.
+func ResetUnborn() error {
+	return cwd.ResetUnborn()
+}
+
+func (r *Repo) ResetUnborn() error {
+	if out, err := r.Command("update-ref", "-d", "HEAD").CombinedOutput(); err != nil {
+		return fmt.Errorf("git update-ref failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// CommitIndex commits what is staged with message, skipping hooks when
+// noVerify is set. The error carries git's output, which is where a hook
+// explains a rejection.
+func CommitIndex(message string, noVerify bool) error {
+	return cwd.CommitIndex(message, noVerify)
+}
+
+func (r *Repo) CommitIndex(message string, noVerify bool) error {
+	args := []string{"commit", "-m", message}
+	if noVerify {
+		args = []string{"commit", "--no-verify", "-m", message}
+	}
+	if out, err := r.Command(args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestStageFrom_UsesSnapshotNotWorktree(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("a.txt", "one\n")
+	r.write("gone.txt", "x\n")
+	r.run("add", ".")
+	r.run("commit", "-qm", "init")
+
+	r.write("a.txt", "one\ntwo\n")
+	r.run("add", "a.txt")
+	r.run("rm", "-q", "gone.txt")
+	r.write("a.txt", "one\ntwo\nunstaged\n")
+
+	tree, err := r.WriteTree()
+	if err != nil {
+		t.Fatalf("WriteTree: %v", err)
+	}
+	r.run("reset", "-q")
+	if err := r.StageFrom(tree, []string{"a.txt", "gone.txt"}); err != nil {
+		t.Fatalf("StageFrom: %v", err)
+	}
+	if got := r.run("diff", "--cached", "--name-status"); got != "M\ta.txt\nD\tgone.txt\n" {
+		t.Errorf("staged = %q", got)
+	}
+	if got := r.run("show", ":a.txt"); got != "one\ntwo\n" {
+		t.Errorf("a.txt staged as %q, want the snapshot's content", got)
+	}
+
+	r.run("reset", "-q")
+	if err := r.ReadTree(tree); err != nil {
+		t.Fatalf("ReadTree: %v", err)
+	}
+	if got, _ := r.WriteTree(); got != tree {
+		t.Errorf("index after ReadTree is %s, want %s", got, tree)
+	}
+}
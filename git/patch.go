@@ -0,0 +1,115 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FileDiff is one file's section of a unified diff.
+type FileDiff struct {
+	Path   string
+	Header string   // the diff --git line through +++, before the first hunk
+	Hunks  []string // each from its @@ line up to the next one
+}
+
+// ParseDiff splits diff into file sections and their hunks.
+func ParseDiff(diff string) []FileDiff {
+	var (
+		files []FileDiff
+		cur   *FileDiff
+		hunk  strings.Builder
+	)
+	flushHunk := func() {
+		if cur != nil && hunk.Len() > 0 {
+			cur.Hunks = append(cur.Hunks, hunk.String())
+		}
+		hunk.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flushHunk()
+			files = append(files, FileDiff{Header: line})
+			cur = &files[len(files)-1]
+		case cur == nil:
+		case strings.HasPrefix(line, "@@"):
+			flushHunk()
+			hunk.WriteString(line)
+		case hunk.Len() > 0:
+			hunk.WriteString(line)
+		default:
+			cur.Header += line
+		}
+	}
+	flushHunk()
+
+	for i := range files {
+		files[i].Path = headerPath(files[i].Header)
+	}
+	return files
+}
+
+// Patch is the file header followed by the given hunks (indexes from 0),
+// ready for git apply.
+func (f FileDiff) Patch(hunks []int) string {
+	var b strings.Builder
+	b.WriteString(f.Header)
+	for _, i := range hunks {
+		b.WriteString(f.Hunks[i])
+	}
+	return b.String()
+}
+
+// headerPath finds the post-image path of a file section, falling back to
+// the pre-image path for deletions.
+func headerPath(header string) string {
+	lines := strings.Split(header, "\n")
+	for _, side := range []struct{ marker, prefix string }{
+		{"+++ ", "b/"}, {"--- ", "a/"}, {"rename to ", ""}, {"copy to ", ""},
+	} {
+		for _, l := range lines {
+			if p, ok := strings.CutPrefix(l, side.marker); ok && p != "/dev/null" {
+				return strings.TrimPrefix(unquotePath(p), side.prefix)
+			}
+		}
+	}
+
+	first := strings.TrimPrefix(lines[0], "diff --git ")
+	if i := strings.LastIndex(first, ` "b/`); i >= 0 {
+		return strings.TrimPrefix(unquotePath(first[i+1:]), "b/")
+	}
+	if i := strings.LastIndex(first, " b/"); i >= 0 {
+		return first[i+3:]
+	}
+	return first
+}
+
+// unquotePath undoes git's C-quoting of unusual paths and drops the tab it
+// appends to paths containing spaces.
+func unquotePath(p string) string {
+	p = strings.TrimSuffix(p, "\t")
+	if strings.HasPrefix(p, `"`) {
+		if u, err := strconv.Unquote(p); err == nil {
+			return u
+		}
+	}
+	return p
+}
+
+// ApplyCached applies patch to the index only.
+func ApplyCached(patch string) error {
+	return cwd.ApplyCached(patch)
+}
+
+// ApplyCached applies patch to the index only. Whitespace in context lines
+// is matched loosely because diffs for the model ignore whitespace changes.
+func (r *Repo) ApplyCached(patch string) error {
+	cmd := r.Command("apply", "--cached", "--ignore-whitespace", "-")
+	cmd.Stdin = strings.NewReader(patch)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git apply failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDiff_SplitsFilesAndHunks(t *testing.T) {
+	diff := "diff --git a/a.go b/a.go\nindex 1..2 100644\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-x\n+y\n@@ -9 +9 @@ func f()\n-p\n+q\n" +
+		"diff --git \"a/t\\tb.go\" \"b/t\\tb.go\"\ndeleted file mode 100644\n--- \"a/t\\tb.go\"\n+++ /dev/null\n@@ -1 +0,0 @@\n-z\n" +
+		"diff --git a/x.lock b/x.lock\n(modified, contents not shown: diff.exclude)\n"
+
+	files := ParseDiff(diff)
+	if len(files) != 3 {
+		t.Fatalf("got %d files, want 3", len(files))
+	}
+	if files[0].Path != "a.go" || len(files[0].Hunks) != 2 || !strings.HasPrefix(files[0].Hunks[1], "@@ -9 +9 @@ func f()\n") {
+		t.Errorf("unexpected first file: %+v", files[0])
+	}
+	if files[1].Path != "t\tb.go" {
+		t.Errorf("deleted quoted path = %q", files[1].Path)
+	}
+	if files[2].Path != "x.lock" || len(files[2].Hunks) != 0 {
+		t.Errorf("unexpected stub: %+v", files[2])
+	}
+}
+
+func TestApplyCached_StagesOneHunk(t *testing.T) {
+	r := newTestRepo(t)
+	var lines []string
+	for i := range 30 {
+		lines = append(lines, strings.Repeat("x", i+1))
+	}
+	write := func() { r.write("f.txt", strings.Join(lines, "\n")+"\n") }
+	write()
+	r.run("add", "f.txt")
+	r.run("commit", "-qm", "init")
+
+	lines[1], lines[25] = "first", "second"
+	write()
+	r.run("add", "f.txt")
+	diff, err := r.Diff()
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	r.run("reset", "-q")
+
+	fd := ParseDiff(diff)[0]
+	if len(fd.Hunks) != 2 {
+		t.Fatalf("got %d hunks, want 2", len(fd.Hunks))
+	}
+	if err := r.ApplyCached(fd.Patch([]int{1})); err != nil {
+		t.Fatalf("ApplyCached: %v", err)
+	}
+	staged := r.run("diff", "--cached")
+	if !strings.Contains(staged, "+second") || strings.Contains(staged, "+first") {
+		t.Errorf("expected only the second hunk staged:\n%s", staged)
+	}
+}
@@ -0,0 +1,65 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dinoDanic/diny/logging"
+)
+
+// Repo is a git worktree. Its commands run in Dir; a zero Repo runs them
+// in the process working directory, which is what the package-level
+// functions use (diny's -C flag changes that directory up front).
+type Repo struct {
+	Dir string
+}
+
+var cwd = &Repo{}
+
+// Open returns the repository containing path, bound to its top level.
+func Open(path string) (*Repo, error) {
+	root, err := (&Repo{Dir: path}).Root()
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{Dir: root}, nil
+}
+
+// Command is a git command run in the worktree.
+func (r *Repo) Command(args ...string) *logging.Cmd {
+	cmd := logging.Command("git", args...)
+	cmd.Dir = r.Dir
+	return cmd
+}
+
+// Root is the top level of the worktree.
+func (r *Repo) Root() (string, error) {
+	return r.revParse("--show-toplevel")
+}
+
+// GitDir is the repository's git directory, which for linked worktrees
+// and submodules is not <root>/.git.
+func (r *Repo) GitDir() (string, error) {
+	return r.revParse("--absolute-git-dir")
+}
+
+func (r *Repo) revParse(flag string) (string, error) {
+	out, err := r.Command("rev-parse", flag).Output()
+	if err != nil {
+		return "", fmt.Errorf("not in a git repository")
+	}
+	path := strings.TrimSuffix(string(out), "\n")
+	if path == "" {
+		return "", fmt.Errorf("not in a git worktree")
+	}
+	return path, nil
+}
+
+// splitZ splits -z output into its NUL-terminated fields.
+func splitZ(out []byte) []string {
+	s := strings.TrimSuffix(string(out), "\x00")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\x00")
+}
@@ -0,0 +1,134 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RangeCommit is one commit of a revision range.
+type RangeCommit struct {
+	Hash    string
+	Parents []string
+	Message string
+}
+
+// Subject is the first line of the message.
+func (c RangeCommit) Subject() string {
+	return strings.SplitN(c.Message, "\n", 2)[0]
+}
+
+// IsMerge reports whether the commit has more than one parent.
+func (c RangeCommit) IsMerge() bool {
+	return len(c.Parents) > 1
+}
+
+// GetRangeCommits lists the commits of a revision range such as main..HEAD,
+// oldest first.
+func GetRangeCommits(rng string) ([]RangeCommit, error) {
+	return cwd.RangeCommits(rng)
+}
+
+func (r *Repo) RangeCommits(rng string) ([]RangeCommit, error) {
+	out, err := r.Command("log", "--reverse", "--topo-order", "--format=%H%x00%P%x00%B%x1e", rng, "--").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits in %s: %w", rng, err)
+	}
+	var commits []RangeCommit
+	for _, record := range strings.Split(string(out), "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		commits = append(commits, RangeCommit{
+			Hash:    fields[0],
+			Parents: strings.Fields(fields[1]),
+			Message: strings.TrimSpace(fields[2]),
+		})
+	}
+	return commits, nil
+}
+
+// GetCommitDiff is the diff a single commit introduced, with excluded files
+// reduced to a note as in Diff.
+func GetCommitDiff(hash string) (string, error) {
+	return cwd.CommitDiff(hash)
+}
+
+func (r *Repo) CommitDiff(hash string) (string, error) {
+	base, err := r.parentOrEmptyTree(hash)
+	if err != nil {
+		return "", err
+	}
+	diff, err := r.diffWithExcludes(base, hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff of %s: %w", hash, err)
+	}
+	return diff, nil
+}
+
+// rewordEditor is run by git as the sequence editor. After every pick of
+// a commit with a new message it adds an exec that amends that message in.
+const rewordEditor = `todo="$1"
+while IFS= read -r line; do
+	printf '%s\n' "$line"
+	case "$line" in
+	pick\ *|p\ *)
+		hash=$(git rev-parse "$(printf '%s' "$line" | cut -d' ' -f2)")
+		if [ -f "$DINY_REWORD_DIR/$hash" ]; then
+			printf 'exec git commit --amend --no-verify --allow-empty --cleanup=whitespace -F "%s"\n' "$DINY_REWORD_DIR/$hash"
+		fi
+		;;
+	esac
+done < "$todo" > "$todo.diny" && mv "$todo.diny" "$todo"
+`
+
+// Reword rewrites the messages of commits between base and HEAD with an
+// automated interactive rebase; messages maps full hashes to new messages.
+// An empty base rebases from the root. keepMerges rebases with
+// --rebase-merges so merge commits survive. Uncommitted changes are
+// stashed and reapplied. If the rebase fails it is aborted, leaving the
+// branch as it was.
+func Reword(base string, messages map[string]string, keepMerges bool) error {
+	return cwd.Reword(base, messages, keepMerges)
+}
+
+func (r *Repo) Reword(base string, messages map[string]string, keepMerges bool) error {
+	dir, err := os.MkdirTemp("", "diny-reword-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for hash, msg := range messages {
+		if err := os.WriteFile(filepath.Join(dir, hash), []byte(msg+"\n"), 0644); err != nil {
+			return fmt.Errorf("failed to write message for %s: %w", hash, err)
+		}
+	}
+	editor := filepath.Join(dir, "sequence-editor.sh")
+	if err := os.WriteFile(editor, []byte(rewordEditor), 0755); err != nil {
+		return fmt.Errorf("failed to write sequence editor: %w", err)
+	}
+
+	args := []string{"rebase", "--interactive", "--autostash"}
+	if keepMerges {
+		args = append(args, "--rebase-merges")
+	}
+	if base == "" {
+		args = append(args, "--root")
+	} else {
+		args = append(args, base)
+	}
+	cmd := r.Command(args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_SEQUENCE_EDITOR=sh '"+filepath.ToSlash(editor)+"'",
+		"GIT_EDITOR=:",
+		"DINY_REWORD_DIR="+filepath.ToSlash(dir),
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		_ = r.Command("rebase", "--abort").Run()
+		return fmt.Errorf("rebase failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -1,91 +1,116 @@
 package git
 
 import (
-	"os/exec"
+	"fmt"
+	"strconv"
 	"strings"
 )
 
 type StagedFile struct {
-	Status string // "A", "M", "D", "R"
+	Status string // "A", "M", "D", "R", "C"
 	Path   string
+	From   string // source path of a rename or copy
 }
 
 func GetStagedFiles() ([]StagedFile, error) {
-	cmd := exec.Command("git", "diff", "--cached", "--name-status")
-	output, err := cmd.Output()
+	return cwd.StagedFiles()
+}
+
+// StagedFiles lists the files changed in the index.
+func (r *Repo) StagedFiles() ([]StagedFile, error) {
+	output, err := r.Command("diff", "--cached", "--name-status", "-z").Output()
 	if err != nil {
 		return nil, err
 	}
+	return parseNameStatus(output), nil
+}
 
-	raw := strings.TrimSpace(string(output))
-	if raw == "" {
-		return nil, nil
-	}
-
+// parseNameStatus reads `--name-status -z` output: a status field then a
+// path, or two paths for renames and copies (R100\0old\0new). Scores are
+// dropped so renames and copies are plain "R" and "C".
+func parseNameStatus(output []byte) []StagedFile {
+	fields := splitZ(output)
 	var files []StagedFile
-	for _, line := range strings.Split(raw, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		parts := strings.SplitN(line, "\t", 2)
-		if len(parts) < 2 {
-			continue
-		}
-		status := parts[0]
-		path := parts[1]
-
-		// Renames show as R100\told\tnew — normalize to "R" with new path
-		if strings.HasPrefix(status, "R") {
-			status = "R"
-			renameParts := strings.SplitN(path, "\t", 2)
-			if len(renameParts) == 2 {
-				path = renameParts[1]
+	for i := 0; i+1 < len(fields); i += 2 {
+		status, path := fields[i], fields[i+1]
+		f := StagedFile{Status: status, Path: path}
+		if strings.HasPrefix(status, "R") || strings.HasPrefix(status, "C") {
+			if i+2 >= len(fields) {
+				break
 			}
+			f = StagedFile{Status: status[:1], Path: fields[i+2], From: path}
+			i++
 		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// FileStat is one line of `git diff --numstat`. Binary files report no
+// line counts.
+type FileStat struct {
+	Path    string
+	Added   int
+	Deleted int
+	Binary  bool
+}
+
+// GetStagedStats returns per-file insertion and deletion counts for the
+// index. Renames are reported as a delete plus an add so every path is
+// plain.
+func GetStagedStats() ([]FileStat, error) {
+	return cwd.StagedStats()
+}
 
-		files = append(files, StagedFile{Status: status, Path: path})
+func (r *Repo) StagedStats() ([]FileStat, error) {
+	output, err := r.Command("diff", "--cached", "--numstat", "--no-renames", "-z").Output()
+	if err != nil {
+		return nil, err
 	}
 
-	return files, nil
+	var stats []FileStat
+	for _, rec := range splitZ(output) {
+		parts := strings.SplitN(rec, "\t", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		st := FileStat{Path: parts[2]}
+		if parts[0] == "-" && parts[1] == "-" {
+			st.Binary = true
+		} else {
+			st.Added, _ = strconv.Atoi(parts[0])
+			st.Deleted, _ = strconv.Atoi(parts[1])
+		}
+		stats = append(stats, st)
+	}
+	return stats, nil
 }
 
 // GetUnstagedFiles returns modified, deleted, and untracked files not yet staged.
 func GetUnstagedFiles() ([]StagedFile, error) {
+	return cwd.UnstagedFiles()
+}
+
+func (r *Repo) UnstagedFiles() ([]StagedFile, error) {
 	var files []StagedFile
 	seen := map[string]bool{}
 
 	// 1. Modified/deleted unstaged changes (working tree vs index)
-	diffOut, err := exec.Command("git", "diff", "--name-status").Output()
+	diffOut, err := r.Command("diff", "--name-status", "-z").Output()
 	if err == nil {
-		for _, line := range strings.Split(strings.TrimSpace(string(diffOut)), "\n") {
-			if line == "" {
-				continue
-			}
-			parts := strings.SplitN(line, "\t", 2)
-			if len(parts) < 2 {
-				continue
-			}
-			status, path := parts[0], parts[1]
-			if seen[path] {
+		for _, f := range parseNameStatus(diffOut) {
+			if seen[f.Path] {
 				continue
 			}
-			seen[path] = true
-			switch status {
-			case "M":
-				files = append(files, StagedFile{Status: "M", Path: path})
-			case "D":
-				files = append(files, StagedFile{Status: "D", Path: path})
-			default:
-				files = append(files, StagedFile{Status: status, Path: path})
-			}
+			seen[f.Path] = true
+			files = append(files, f)
 		}
 	}
 
 	// 2. Untracked (new) files
-	lsOut, err := exec.Command("git", "ls-files", "--others", "--exclude-standard").Output()
+	lsOut, err := r.Command("ls-files", "--others", "--exclude-standard", "-z").Output()
 	if err == nil {
-		for _, path := range strings.Split(strings.TrimSpace(string(lsOut)), "\n") {
+		for _, path := range splitZ(lsOut) {
 			if path == "" || seen[path] {
 				continue
 			}
@@ -98,10 +123,66 @@ func GetUnstagedFiles() ([]StagedFile, error) {
 }
 
 func GetCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	output, err := cmd.Output()
+	return cwd.CurrentBranch()
+}
+
+func (r *Repo) CurrentBranch() (string, error) {
+	// symbolic-ref also names an unborn branch, before the first commit.
+	if output, err := r.Command("symbolic-ref", "--short", "-q", "HEAD").Output(); err == nil {
+		return strings.TrimSpace(string(output)), nil
+	}
+	output, err := r.Command("rev-parse", "--abbrev-ref", "HEAD").Output()
 	if err != nil {
 		return "", err
 	}
 	return strings.TrimSpace(string(output)), nil
 }
+
+// GetHead returns the full hash of HEAD.
+func GetHead() (string, error) {
+	return cwd.Head()
+}
+
+func (r *Repo) Head() (string, error) {
+	output, err := r.Command("rev-parse", "--verify", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetHeadIfAny is GetHead, except that it returns "" rather than an error
+// on a branch with no commits yet.
+func GetHeadIfAny() (string, error) {
+	return cwd.HeadIfAny()
+}
+
+func (r *Repo) HeadIfAny() (string, error) {
+	if out, err := r.Command("rev-parse", "--verify", "--quiet", "HEAD").Output(); err == nil {
+		return strings.TrimSpace(string(out)), nil
+	}
+	if _, err := r.GitDir(); err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+// GetRemoteBranchesContaining lists the remote-tracking branches that
+// already have commit, i.e. where it has been pushed to.
+func GetRemoteBranchesContaining(commit string) ([]string, error) {
+	return cwd.RemoteBranchesContaining(commit)
+}
+
+func (r *Repo) RemoteBranchesContaining(commit string) ([]string, error) {
+	output, err := r.Command("branch", "-r", "--contains", commit, "--format=%(refname:short)").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote branches: %w", err)
+	}
+	var branches []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			branches = append(branches, line)
+		}
+	}
+	return branches, nil
+}
@@ -0,0 +1,64 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseNameStatus_PathsAreTakenVerbatim(t *testing.T) {
+	out := []byte("M\x00tab\there.go\x00R087\x00old.go\x00new\nline.go\x00C100\x00a.go\x00ü.go\x00D\x00gone.go\x00")
+	want := []StagedFile{
+		{Status: "M", Path: "tab\there.go"},
+		{Status: "R", Path: "new\nline.go", From: "old.go"},
+		{Status: "C", Path: "ü.go", From: "a.go"},
+		{Status: "D", Path: "gone.go"},
+	}
+	if got := parseNameStatus(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNameStatus =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestOpen_BindsToTopLevel(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "odd\tname.txt"), []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Open(sub)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	root, _ := filepath.EvalSymlinks(dir)
+	if got, _ := filepath.EvalSymlinks(r.Dir); got != root {
+		t.Errorf("Dir = %q, want %q", r.Dir, root)
+	}
+
+	if err := r.Command("add", "-A").Run(); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	files, err := r.StagedFiles()
+	if err != nil {
+		t.Fatalf("StagedFiles: %v", err)
+	}
+	want := []StagedFile{{Status: "A", Path: "sub/odd\tname.txt"}}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("StagedFiles = %#v, want %#v", files, want)
+	}
+
+	if _, err := Open(t.TempDir()); err == nil {
+		t.Errorf("Open outside a repository should fail")
+	}
+}
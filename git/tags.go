@@ -2,7 +2,6 @@ package git
 
 import (
 	"fmt"
-	"os/exec"
 	"strings"
 )
 
@@ -12,7 +11,7 @@ type CommitInfo struct {
 }
 
 func GetTags() ([]string, error) {
-	cmd := exec.Command("git", "tag", "-l", "--sort=-version:refname")
+	cmd := cwd.Command("tag", "-l", "--sort=-version:refname")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tags: %w", err)
@@ -34,7 +33,7 @@ func GetTags() ([]string, error) {
 }
 
 func GetRecentCommits(limit int) ([]CommitInfo, error) {
-	cmd := exec.Command("git", "log",
+	cmd := cwd.Command("log",
 		fmt.Sprintf("--pretty=format:%%h|||%%s"),
 		"--no-merges",
 		fmt.Sprintf("-n%d", limit),
@@ -65,20 +64,21 @@ func GetRecentCommits(limit int) ([]CommitInfo, error) {
 }
 
 func GetDiffBetweenRefs(ref1, ref2 string) (string, error) {
-	cmd := exec.Command("git", "diff", ref1+"..."+ref2,
-		"-U3", "--no-color", "--ignore-all-space", "--ignore-blank-lines",
-		":(exclude)*.lock", ":(exclude)*package-lock.json", ":(exclude)*yarn.lock",
-		":(exclude)node_modules/", ":(exclude)dist/", ":(exclude)build/",
-	)
-	output, err := cmd.Output()
+	return cwd.DiffBetween(ref1, ref2)
+}
+
+// DiffBetween is the diff from the merge base of ref1 and ref2 to ref2,
+// with excluded files reduced to a note as in Diff.
+func (r *Repo) DiffBetween(ref1, ref2 string) (string, error) {
+	diff, err := r.diffWithExcludes(ref1 + "..." + ref2)
 	if err != nil {
 		return "", fmt.Errorf("failed to get diff between %s and %s: %w", ref1, ref2, err)
 	}
-	return string(output), nil
+	return diff, nil
 }
 
 func GetCommitsBetweenRefs(ref1, ref2 string) ([]string, error) {
-	cmd := exec.Command("git", "log", ref1+".."+ref2,
+	cmd := cwd.Command("log", ref1+".."+ref2,
 		"--pretty=format:%s",
 		"--no-merges",
 	)
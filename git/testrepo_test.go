@@ -0,0 +1,46 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// testRepo is a scratch repository whose helpers fail the test on error.
+type testRepo struct {
+	*Repo
+	t *testing.T
+}
+
+// newTestRepo initialises a repository in a temp dir with a committer
+// identity, skipping the test when git is not installed.
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	r := &testRepo{Repo: &Repo{Dir: t.TempDir()}, t: t}
+	r.run("init", "-q")
+	r.run("config", "user.name", "t")
+	r.run("config", "user.email", "t@t")
+	return r
+}
+
+// run runs git in the repository and returns its combined output.
+func (r *testRepo) run(args ...string) string {
+	r.t.Helper()
+	out, err := r.Command(args...).CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+// write writes a file in the worktree.
+func (r *testRepo) write(name, text string) {
+	r.t.Helper()
+	if err := os.WriteFile(filepath.Join(r.Dir, name), []byte(text), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+}
@@ -0,0 +1,30 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AddTrailers appends trailers ("Key: value") to message with git
+// interpret-trailers, which puts them in a trailer block after a blank
+// line. A trailer the message already has is not repeated.
+func AddTrailers(message string, trailers []string) (string, error) {
+	return cwd.AddTrailers(message, trailers)
+}
+
+func (r *Repo) AddTrailers(message string, trailers []string) (string, error) {
+	if len(trailers) == 0 {
+		return message, nil
+	}
+	args := []string{"interpret-trailers", "--if-exists", "addIfDifferent"}
+	for _, t := range trailers {
+		args = append(args, "--trailer", t)
+	}
+	cmd := r.Command(args...)
+	cmd.Stdin = strings.NewReader(message + "\n")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git interpret-trailers failed: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
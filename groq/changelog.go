@@ -1,7 +1,11 @@
 package groq
 
-import "github.com/dinoDanic/diny/config"
+import (
+	"context"
 
-func CreateChangelogWithGroq(prompt string, cfg *config.Config) (string, error) {
-	return CreateTimelineWithGroq(prompt, cfg)
+	"github.com/dinoDanic/diny/config"
+)
+
+func CreateChangelogWithGroq(ctx context.Context, prompt string, cfg *config.Config) (string, error) {
+	return CreateTimelineWithGroq(ctx, prompt, cfg)
 }
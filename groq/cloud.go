@@ -0,0 +1,124 @@
+package groq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/dinoDanic/diny/backend"
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/privacy"
+	"github.com/dinoDanic/diny/server"
+	"github.com/dinoDanic/diny/version"
+)
+
+// cloudProvider talks to the hosted diny server, which builds the system
+// prompt and forwards to the model on our behalf.
+type cloudProvider struct{}
+
+func (p *cloudProvider) Name() string {
+	return string(config.ProviderDiny)
+}
+
+func (p *cloudProvider) request(reqType string, userPrompt string, cfg *config.Config, extras *RequestExtras) Request {
+	var privacyCfg config.PrivacyConfig
+	if cfg != nil {
+		privacyCfg = cfg.Privacy
+	}
+	id := privacy.Collect(privacyCfg)
+
+	// The hosted service only knows commit, split and timeline. A summary
+	// prompt spells out its own instructions, so it travels as a timeline,
+	// the free-form type, as it always has.
+	if reqType == "summary" {
+		reqType = "timeline"
+	}
+
+	payload := Request{
+		Type:       reqType,
+		Config:     cfg,
+		Version:    version.Get(),
+		UserPrompt: userPrompt,
+		Name:       id.Name,
+		Email:      id.Email,
+		RepoName:   id.RepoName,
+		System:     id.System,
+	}
+	if extras != nil {
+		payload.PreviousPlans = extras.PreviousPlans
+		payload.Feedback = extras.Feedback
+	}
+	return payload
+}
+
+func (p *cloudProvider) url() string {
+	return server.ServerConfig.BaseURL + "/api/requests"
+}
+
+// Preview ignores stream: the hosted service always answers in one piece.
+func (p *cloudProvider) Preview(reqType string, userPrompt string, cfg *config.Config, extras *RequestExtras, stream bool) Preview {
+	return Preview{
+		URL:     p.url(),
+		Headers: []string{"Content-Type: application/json"},
+		Body:    p.request(reqType, userPrompt, cfg, extras),
+	}
+}
+
+func (p *cloudProvider) Generate(ctx context.Context, reqType string, userPrompt string, cfg *config.Config, extras *RequestExtras) (*responseData, error) {
+	buf, err := json.Marshal(p.request(reqType, userPrompt, cfg, extras))
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx,
+		http.MethodPost,
+		p.url(),
+		bytes.NewReader(buf),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, backend.Transport(p.Name(), fmt.Errorf("do request: %w", err))
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, backend.Transport(p.Name(), fmt.Errorf("read response: %w", err))
+	}
+
+	// Proxies and the hosting platform answer 429/5xx with HTML, so look at
+	// the status before trying to decode.
+	var out response
+	decodeErr := json.Unmarshal(body, &out)
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500 {
+		msg := ""
+		if decodeErr == nil && out.Error != nil {
+			msg = *out.Error
+		}
+		return nil, backend.Status(p.Name(), res, msg)
+	}
+	if decodeErr != nil {
+		return nil, backend.Invalid(p.Name(), fmt.Errorf("decode response (status %d): %w", res.StatusCode, decodeErr))
+	}
+
+	if out.Error != nil {
+		return nil, fmt.Errorf("%s", *out.Error)
+	}
+
+	if out.Data == nil {
+		return nil, backend.Invalid(p.Name(), fmt.Errorf("no data in response"))
+	}
+
+	return out.Data, nil
+}
@@ -0,0 +1,35 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/server"
+)
+
+func TestCloudProvider_SendsSummaryAsTimeline(t *testing.T) {
+	var sent Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"data":{"message":"File: a.go (modified)\n- x"}}`))
+	}))
+	defer srv.Close()
+
+	prev := server.ServerConfig.BaseURL
+	server.ServerConfig.BaseURL = srv.URL
+	defer func() { server.ServerConfig.BaseURL = prev }()
+
+	cfg := &config.Config{Commit: config.CommitConfig{Tone: config.Casual, Length: config.Short}}
+	if _, err := CreateSummaryWithGroq(context.Background(), "Summarise this part of a git diff", cfg); err != nil {
+		t.Fatalf("CreateSummaryWithGroq: %v", err)
+	}
+	if sent.Type != "timeline" {
+		t.Errorf("hosted request type = %q, want timeline", sent.Type)
+	}
+}
@@ -0,0 +1,38 @@
+package groq
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dinoDanic/diny/config"
+)
+
+// DryRun renders the request that generating reqType from userPrompt would
+// send to the configured backend, after redaction, without sending it.
+// Commit messages are shown as the streaming request the TUI makes.
+func DryRun(reqType string, userPrompt string, cfg *config.Config, extras *RequestExtras) (string, error) {
+	provider, err := NewProvider(cfg)
+	if err != nil {
+		return "", err
+	}
+	p := provider.Preview(reqType, scrub(userPrompt, cfg), cfg, extras, reqType == "commit")
+
+	var body strings.Builder
+	enc := json.NewEncoder(&body)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(p.Body); err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "POST %s\n", p.URL)
+	for _, h := range p.Headers {
+		b.WriteString(h)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(strings.TrimSpace(body.String()))
+	return b.String(), nil
+}
@@ -1,28 +1,25 @@
 package groq
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"runtime"
 	"time"
 
+	"github.com/dinoDanic/diny/backend"
 	"github.com/dinoDanic/diny/config"
-	"github.com/dinoDanic/diny/git"
-	"github.com/dinoDanic/diny/server"
-	"github.com/dinoDanic/diny/version"
+	"github.com/dinoDanic/diny/logging"
+	"github.com/dinoDanic/diny/redact"
 )
 
 type Request struct {
 	Type          string         `json:"type"`
 	UserPrompt    string         `json:"userPrompt"`
 	Version       string         `json:"version"`
-	Name          string         `json:"name"`
-	Email         string         `json:"email"`
-	RepoName      string         `json:"repoName"`
+	Name          string         `json:"name,omitempty"`
+	Email         string         `json:"email,omitempty"`
+	RepoName      string         `json:"repoName,omitempty"`
 	Config        *config.Config `json:"config"`
 	System        string         `json:"system,omitempty"`
 	PreviousPlans [][]SplitGroup `json:"previousPlans,omitempty"`
@@ -30,10 +27,18 @@ type Request struct {
 }
 
 type SplitGroup struct {
-	Order   int      `json:"order"`
-	Type    string   `json:"type"`
-	Message string   `json:"message"`
-	Files   []string `json:"files"`
+	Order   int       `json:"order"`
+	Type    string    `json:"type"`
+	Message string    `json:"message"`
+	Files   []string  `json:"files"`
+	Hunks   []HunkRef `json:"hunks,omitempty"`
+}
+
+// HunkRef assigns one hunk of a file to a group, for files whose changes
+// belong to more than one commit. Hunk counts the file's @@ hunks from 1.
+type HunkRef struct {
+	File string `json:"file"`
+	Hunk int    `json:"hunk"`
 }
 
 type responseData struct {
@@ -51,84 +56,151 @@ type RequestExtras struct {
 	Feedback      string
 }
 
-func doRequest(reqType string, userPrompt string, cfg *config.Config, extras *RequestExtras) (*responseData, error) {
-	payload := Request{
-		Type:       reqType,
-		Config:     cfg,
-		Version:    version.Get(),
-		UserPrompt: userPrompt,
-		Name:       git.GetGitName(),
-		Email:      git.GetGitEmail(),
-		RepoName:   git.GetRepoName(),
-		System:     runtime.GOOS,
-	}
-	if extras != nil {
-		payload.PreviousPlans = extras.PreviousPlans
-		payload.Feedback = extras.Feedback
+// scrub replaces likely secrets in the prompt with placeholders. Every
+// request passes through here before it reaches a provider.
+func scrub(userPrompt string, cfg *config.Config) string {
+	if cfg == nil {
+		return userPrompt
 	}
+	out, _ := redact.Apply(userPrompt, cfg.Redact)
+	return out
+}
 
-	buf, err := json.Marshal(payload)
+func doRequest(ctx context.Context, reqType string, userPrompt string, cfg *config.Config, extras *RequestExtras) (*responseData, error) {
+	provider, err := NewProvider(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("marshal payload: %w", err)
+		return nil, err
 	}
+	userPrompt = scrub(userPrompt, cfg)
+	data, err := backend.Retry(ctx, backend.DefaultPolicy, func() (*responseData, error) {
+		start := time.Now()
+		data, err := provider.Generate(ctx, reqType, userPrompt, cfg, extras)
+		var reply string
+		if data != nil {
+			reply = data.Message
+			if len(data.Groups) > 0 {
+				buf, _ := json.Marshal(data.Groups)
+				reply = string(buf)
+			}
+		}
+		logCall(provider.Name(), reqType, userPrompt, reply, err, start)
+		return data, err
+	})
+	if err != nil && ctx.Err() != nil {
+		// Report the cancellation itself rather than the transport error it caused.
+		return nil, ctx.Err()
+	}
+	return data, err
+}
 
-	req, err := http.NewRequestWithContext(context.Background(),
-		http.MethodPost,
-		server.ServerConfig.BaseURL+"/api/requests",
-		bytes.NewReader(buf),
-	)
+// logCall records one attempt at a backend request. Bodies are only
+// logged with --verbose.
+func logCall(name, reqType, prompt, reply string, err error, start time.Time) {
+	args := []any{
+		"backend", name,
+		"type", reqType,
+		"sent", len(prompt),
+		"received", len(reply),
+		"duration", time.Since(start).Round(time.Millisecond),
+	}
 	if err != nil {
-		return nil, fmt.Errorf("new request: %w", err)
+		var be *backend.Error
+		if errors.As(err, &be) {
+			args = append(args, "kind", be.Kind.String())
+			if be.StatusCode != 0 {
+				args = append(args, "status", be.StatusCode)
+			}
+		}
+		logging.Warn("backend call failed", append(args, "error", logging.Scrub(err.Error()))...)
+		return
 	}
+	logging.Info("backend call", args...)
+	logging.Debug("backend exchange", "type", reqType, "prompt", logging.Body(prompt), "reply", logging.Body(reply))
+}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{Timeout: 60 * time.Second}
-	res, err := client.Do(req)
+func sendRequest(ctx context.Context, reqType string, userPrompt string, cfg *config.Config) (string, error) {
+	data, err := doRequest(ctx, reqType, userPrompt, cfg, nil)
 	if err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
+		return "", err
 	}
-	defer res.Body.Close()
-
-	body, _ := io.ReadAll(res.Body)
-
-	var out response
-	if err := json.Unmarshal(body, &out); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+	if data.Message == "" {
+		return "", fmt.Errorf("empty message from server")
 	}
+	return data.Message, nil
+}
 
-	if out.Error != nil {
-		return nil, fmt.Errorf("%s", *out.Error)
+// sendRequestStream is sendRequest for callers that want incremental
+// output. Providers that cannot stream deliver the whole message as a
+// single chunk.
+func sendRequestStream(ctx context.Context, reqType string, userPrompt string, cfg *config.Config, onChunk func(string)) (string, error) {
+	provider, err := NewProvider(cfg)
+	if err != nil {
+		return "", err
 	}
 
-	if out.Data == nil {
-		return nil, fmt.Errorf("no data in response")
-	}
+	userPrompt = scrub(userPrompt, cfg)
 
-	return out.Data, nil
-}
+	sp, ok := provider.(StreamingProvider)
+	if !ok {
+		msg, err := sendRequest(ctx, reqType, userPrompt, cfg)
+		if err == nil && onChunk != nil {
+			onChunk(msg)
+		}
+		return msg, err
+	}
 
-func sendRequest(reqType string, userPrompt string, cfg *config.Config) (string, error) {
-	data, err := doRequest(reqType, userPrompt, cfg, nil)
+	msg, err := backend.Retry(ctx, backend.DefaultPolicy, func() (string, error) {
+		streamed := false
+		start := time.Now()
+		msg, err := sp.GenerateStream(ctx, reqType, userPrompt, cfg, func(chunk string) {
+			streamed = true
+			if onChunk != nil {
+				onChunk(chunk)
+			}
+		})
+		logCall(provider.Name(), reqType, userPrompt, msg, err, start)
+		if err != nil && streamed {
+			// The user has already seen part of this answer; starting over
+			// would repeat it.
+			return "", backend.Permanent(err)
+		}
+		return msg, err
+	})
 	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
 		return "", err
 	}
-	if data.Message == "" {
-		return "", fmt.Errorf("empty message from server")
+	msg = cleanMessage(msg)
+	if msg == "" {
+		return "", fmt.Errorf("empty message from %s", provider.Name())
 	}
-	return data.Message, nil
+	return msg, nil
 }
 
-func CreateCommitMessageWithGroq(gitDiff string, cfg *config.Config) (string, error) {
-	return sendRequest("commit", gitDiff, cfg)
+func CreateCommitMessageWithGroq(ctx context.Context, gitDiff string, cfg *config.Config) (string, error) {
+	return sendRequest(ctx, "commit", gitDiff, cfg)
 }
 
-func CreateTimelineWithGroq(prompt string, cfg *config.Config) (string, error) {
-	return sendRequest("timeline", prompt, cfg)
+// CreateCommitMessageStreamWithGroq generates a commit message, reporting
+// partial text through onChunk while it is being produced.
+func CreateCommitMessageStreamWithGroq(ctx context.Context, gitDiff string, cfg *config.Config, onChunk func(string)) (string, error) {
+	return sendRequestStream(ctx, "commit", gitDiff, cfg, onChunk)
 }
 
-func CreateSplitPlanWithGroq(gitDiff string, cfg *config.Config, extras *RequestExtras) ([]SplitGroup, error) {
-	data, err := doRequest("split", gitDiff, cfg, extras)
+func CreateTimelineWithGroq(ctx context.Context, prompt string, cfg *config.Config) (string, error) {
+	return sendRequest(ctx, "timeline", prompt, cfg)
+}
+
+// CreateSummaryWithGroq summarises one chunk of a diff too large to send
+// whole.
+func CreateSummaryWithGroq(ctx context.Context, prompt string, cfg *config.Config) (string, error) {
+	return sendRequest(ctx, "summary", prompt, cfg)
+}
+
+func CreateSplitPlanWithGroq(ctx context.Context, gitDiff string, cfg *config.Config, extras *RequestExtras) ([]SplitGroup, error) {
+	data, err := doRequest(ctx, "split", gitDiff, cfg, extras)
 	if err != nil {
 		return nil, err
 	}
@@ -137,3 +209,33 @@ func CreateSplitPlanWithGroq(gitDiff string, cfg *config.Config, extras *Request
 	}
 	return data.Groups, nil
 }
+
+// Answer handles a Request received by `diny serve` with the provider
+// configured in cfg. The client's commit settings from req.Config replace
+// cfg.Commit, so everyone using a shared server keeps their own style.
+func Answer(ctx context.Context, req Request, cfg *config.Config) (string, []SplitGroup, error) {
+	merged := *cfg
+	if req.Config != nil {
+		merged.Commit = req.Config.Commit
+	}
+
+	var extras *RequestExtras
+	if len(req.PreviousPlans) > 0 || req.Feedback != "" {
+		extras = &RequestExtras{PreviousPlans: req.PreviousPlans, Feedback: req.Feedback}
+	}
+
+	data, err := doRequest(ctx, req.Type, req.UserPrompt, &merged, extras)
+	if err != nil {
+		return "", nil, err
+	}
+	if req.Type == "split" {
+		if len(data.Groups) == 0 {
+			return "", nil, backend.Invalid(string(merged.Provider.Name), fmt.Errorf("empty split plan"))
+		}
+		return "", data.Groups, nil
+	}
+	if data.Message == "" {
+		return "", nil, backend.Invalid(string(merged.Provider.Name), fmt.Errorf("empty message"))
+	}
+	return data.Message, nil, nil
+}
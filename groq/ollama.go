@@ -0,0 +1,102 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dinoDanic/diny/backend"
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/ollama"
+)
+
+// splitPlanSchema is the JSON schema a split plan response must match.
+var splitPlanSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "groups": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "order": {"type": "integer"},
+          "type": {"type": "string"},
+          "message": {"type": "string"},
+          "files": {"type": "array", "items": {"type": "string"}},
+          "hunks": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "properties": {
+                "file": {"type": "string"},
+                "hunk": {"type": "integer"}
+              },
+              "required": ["file", "hunk"]
+            }
+          }
+        },
+        "required": ["order", "type", "message", "files"]
+      }
+    }
+  },
+  "required": ["groups"]
+}`)
+
+// ollamaProvider builds prompts client-side and runs them against a local
+// Ollama server.
+type ollamaProvider struct {
+	client *ollama.Client
+}
+
+func newOllamaProvider(cfg config.OllamaConfig) *ollamaProvider {
+	return &ollamaProvider{client: ollama.New(cfg)}
+}
+
+func (p *ollamaProvider) Name() string {
+	return string(config.ProviderOllama) + " (" + p.client.Model() + ")"
+}
+
+func (p *ollamaProvider) Generate(ctx context.Context, reqType string, userPrompt string, cfg *config.Config, extras *RequestExtras) (*responseData, error) {
+	system := buildSystemPrompt(reqType, cfg)
+	prompt := buildUserPrompt(userPrompt, extras)
+
+	if reqType == "split" {
+		out, err := p.client.Generate(ctx, system, prompt, splitPlanSchema)
+		if err != nil {
+			return nil, err
+		}
+		groups, err := parseSplitGroups(out)
+		if err != nil {
+			return nil, backend.Invalid(p.Name(), fmt.Errorf("ollama returned an invalid split plan: %w", err))
+		}
+		return &responseData{Groups: groups}, nil
+	}
+
+	out, err := p.client.Generate(ctx, system, prompt, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &responseData{Message: cleanMessage(out)}, nil
+}
+
+func (p *ollamaProvider) Preview(reqType string, userPrompt string, cfg *config.Config, extras *RequestExtras, stream bool) Preview {
+	system := buildSystemPrompt(reqType, cfg)
+	var body ollama.GenerateRequest
+	switch {
+	case reqType == "split":
+		body = p.client.Body(system, buildUserPrompt(userPrompt, extras), splitPlanSchema, false)
+	case stream:
+		body = p.client.Body(system, userPrompt, nil, true)
+	default:
+		body = p.client.Body(system, buildUserPrompt(userPrompt, extras), nil, false)
+	}
+	return Preview{
+		URL:     p.client.URL(),
+		Headers: []string{"Content-Type: application/json"},
+		Body:    body,
+	}
+}
+
+func (p *ollamaProvider) GenerateStream(ctx context.Context, reqType string, userPrompt string, cfg *config.Config, onChunk func(string)) (string, error) {
+	return p.client.GenerateStream(ctx, buildSystemPrompt(reqType, cfg), userPrompt, onChunk)
+}
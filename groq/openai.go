@@ -0,0 +1,97 @@
+package groq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dinoDanic/diny/backend"
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/openai"
+)
+
+// openAIProvider builds prompts client-side and sends them to any server
+// that implements the OpenAI chat-completions protocol.
+type openAIProvider struct {
+	client *openai.Client
+}
+
+func newOpenAIProvider(cfg config.OpenAIConfig) *openAIProvider {
+	return &openAIProvider{client: openai.New(cfg)}
+}
+
+func (p *openAIProvider) Name() string {
+	return string(config.ProviderOpenAI) + " (" + p.client.Model() + ")"
+}
+
+func (p *openAIProvider) Generate(ctx context.Context, reqType string, userPrompt string, cfg *config.Config, extras *RequestExtras) (*responseData, error) {
+	messages := []openai.Message{
+		{Role: "system", Content: buildSystemPrompt(reqType, cfg)},
+		{Role: "user", Content: buildUserPrompt(userPrompt, extras)},
+	}
+
+	if reqType == "split" {
+		out, err := p.client.Chat(ctx, messages, &openai.ResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &openai.JSONSchema{
+				Name:   "split_plan",
+				Schema: splitPlanSchema,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		groups, err := parseSplitGroups(out)
+		if err != nil {
+			return nil, backend.Invalid(p.Name(), fmt.Errorf("model returned an invalid split plan: %w", err))
+		}
+		return &responseData{Groups: groups}, nil
+	}
+
+	out, err := p.client.Chat(ctx, messages, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &responseData{Message: cleanMessage(out)}, nil
+}
+
+func (p *openAIProvider) Preview(reqType string, userPrompt string, cfg *config.Config, extras *RequestExtras, stream bool) Preview {
+	system := buildSystemPrompt(reqType, cfg)
+	var body openai.ChatRequest
+	switch {
+	case reqType == "split":
+		body = p.client.Body([]openai.Message{
+			{Role: "system", Content: system},
+			{Role: "user", Content: buildUserPrompt(userPrompt, extras)},
+		}, &openai.ResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &openai.JSONSchema{
+				Name:   "split_plan",
+				Schema: splitPlanSchema,
+			},
+		}, false)
+	case stream:
+		body = p.client.Body([]openai.Message{
+			{Role: "system", Content: system},
+			{Role: "user", Content: userPrompt},
+		}, nil, true)
+	default:
+		body = p.client.Body([]openai.Message{
+			{Role: "system", Content: system},
+			{Role: "user", Content: buildUserPrompt(userPrompt, extras)},
+		}, nil, false)
+	}
+
+	headers := []string{"Content-Type: application/json"}
+	if env := cfg.Provider.OpenAI.APIKeyEnv; env != "" {
+		// Never print the key itself.
+		headers = append(headers, "Authorization: Bearer $"+env)
+	}
+	return Preview{URL: p.client.URL(), Headers: headers, Body: body}
+}
+
+func (p *openAIProvider) GenerateStream(ctx context.Context, reqType string, userPrompt string, cfg *config.Config, onChunk func(string)) (string, error) {
+	return p.client.ChatStream(ctx, []openai.Message{
+		{Role: "system", Content: buildSystemPrompt(reqType, cfg)},
+		{Role: "user", Content: userPrompt},
+	}, onChunk)
+}
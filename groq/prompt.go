@@ -0,0 +1,157 @@
+package groq
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dinoDanic/diny/config"
+)
+
+// Prompt construction for providers that talk to a model directly. The
+// hosted diny service builds the equivalent prompts server-side.
+
+func buildSystemPrompt(reqType string, cfg *config.Config) string {
+	switch reqType {
+	case "split":
+		return buildSplitSystemPrompt(cfg)
+	case "timeline":
+		return "You are a senior engineer summarising git history. Answer in concise, well-structured markdown. Do not invent changes that are not in the input."
+	case "summary":
+		return "You condense parts of a large git diff into plain per-file notes that a commit message will later be written from. Follow the requested format exactly, keep file paths verbatim, and do not invent changes that are not in the diff."
+	default:
+		return buildCommitSystemPrompt(cfg)
+	}
+}
+
+func buildCommitSystemPrompt(cfg *config.Config) string {
+	var b strings.Builder
+	b.WriteString("You write git commit messages from a staged diff.\n")
+	b.WriteString("Reply with the commit message only: no preamble, no quotes, no markdown code fences.\n")
+	b.WriteString(styleRules(cfg))
+	return b.String()
+}
+
+func buildSplitSystemPrompt(cfg *config.Config) string {
+	var b strings.Builder
+	b.WriteString("You split a staged git diff into several logical commits.\n")
+	b.WriteString("Group the changed files by concern and order the groups so each commit builds on the previous ones.\n")
+	b.WriteString("Every changed file must appear in exactly one group. Use the file paths exactly as they appear after \"diff --git a/\".\n")
+	b.WriteString("When one file mixes unrelated changes, leave it out of \"files\" and assign its hunks instead: list {\"file\": path, \"hunk\": n} under \"hunks\", where n counts that file's @@ hunks from 1. Every hunk of such a file must appear in exactly one group.\n")
+	b.WriteString("Reply with JSON only, matching this shape:\n")
+	b.WriteString(`{"groups":[{"order":1,"type":"feat","message":"commit message","files":["path/to/file"],"hunks":[{"file":"path/to/other","hunk":1}]}]}`)
+	b.WriteString("\n\"type\" is a conventional commit type (feat, fix, docs, style, refactor, perf, test, chore).\n")
+	b.WriteString("Each \"message\" follows these rules:\n")
+	b.WriteString(styleRules(cfg))
+	return b.String()
+}
+
+// styleRules renders the user's CommitConfig as prompt instructions.
+func styleRules(cfg *config.Config) string {
+	if cfg == nil {
+		return ""
+	}
+	c := cfg.Commit
+
+	var rules []string
+	if c.Conventional {
+		rules = append(rules, "Use conventional commit format: type(scope): subject.")
+	} else {
+		rules = append(rules, "Do not use a conventional commit type prefix.")
+	}
+	if c.Emoji {
+		rules = append(rules, "Start the subject with one fitting emoji.")
+	} else {
+		rules = append(rules, "Do not use emoji.")
+	}
+
+	switch c.Tone {
+	case config.Professional:
+		rules = append(rules, "Tone: professional — formal and precise.")
+	case config.Friendly:
+		rules = append(rules, "Tone: friendly — warm and approachable.")
+	default:
+		rules = append(rules, "Tone: casual — relaxed and conversational.")
+	}
+
+	switch c.Length {
+	case config.Long:
+		rules = append(rules, "Length: subject ≤80 chars, imperative mood, then a blank line and 2-6 terse bullets for context and impact.")
+	case config.Normal:
+		rules = append(rules, "Length: subject ≤70 chars, imperative mood. If needed, add a blank line and 1-3 terse bullets for why/impact.")
+	default:
+		rules = append(rules, "Length: subject only, ≤60 chars, imperative verb first. No body, no bullets.")
+	}
+
+	if ci := strings.TrimSpace(c.CustomInstructions); ci != "" {
+		rules = append(rules, "Additional instructions from the user: "+ci)
+	}
+
+	return "- " + strings.Join(rules, "\n- ") + "\n"
+}
+
+// buildUserPrompt appends split regeneration context (previous plans and
+// feedback) to the user prompt. Commit and timeline prompts already carry
+// their own regenerate/feedback suffixes.
+func buildUserPrompt(userPrompt string, extras *RequestExtras) string {
+	if extras == nil {
+		return userPrompt
+	}
+
+	var b strings.Builder
+	b.WriteString(userPrompt)
+
+	if len(extras.PreviousPlans) > 0 {
+		b.WriteString("\n\nPrevious plans that were rejected (produce a different grouping):\n")
+		for i, plan := range extras.PreviousPlans {
+			buf, err := json.Marshal(plan)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(&b, "%d. %s\n", i+1, buf)
+		}
+	}
+
+	if extras.Feedback != "" {
+		fmt.Fprintf(&b, "\n\nUser feedback on the last plan: %s\n", extras.Feedback)
+	}
+
+	return b.String()
+}
+
+// cleanMessage strips the wrapping that chat models like to add around an
+// otherwise plain commit message.
+func cleanMessage(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "```") {
+		s = strings.TrimPrefix(s, "```")
+		if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+			s = s[idx+1:]
+		}
+		s = strings.TrimSuffix(strings.TrimSpace(s), "```")
+		s = strings.TrimSpace(s)
+	}
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	return s
+}
+
+// parseSplitGroups decodes a model's JSON split plan. It accepts either the
+// {"groups": [...]} envelope or a bare array.
+func parseSplitGroups(s string) ([]SplitGroup, error) {
+	s = cleanMessage(s)
+
+	var envelope struct {
+		Groups []SplitGroup `json:"groups"`
+	}
+	if err := json.Unmarshal([]byte(s), &envelope); err == nil && len(envelope.Groups) > 0 {
+		return envelope.Groups, nil
+	}
+
+	var groups []SplitGroup
+	if err := json.Unmarshal([]byte(s), &groups); err != nil {
+		return nil, fmt.Errorf("decode split plan: %w", err)
+	}
+	return groups, nil
+}
@@ -0,0 +1,55 @@
+package groq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dinoDanic/diny/config"
+)
+
+// Provider is a backend that turns a prompt into a commit message, split
+// plan, timeline, changelog or diff summary. reqType is one of "commit",
+// "split", "timeline" or "summary". Implementations must abort promptly
+// when ctx is cancelled.
+type Provider interface {
+	Name() string
+	Generate(ctx context.Context, reqType string, userPrompt string, cfg *config.Config, extras *RequestExtras) (*responseData, error)
+	// Preview returns the HTTP request Generate (or GenerateStream, when
+	// stream is set) would make, without making it.
+	Preview(reqType string, userPrompt string, cfg *config.Config, extras *RequestExtras, stream bool) Preview
+}
+
+// Preview is an HTTP request a provider would send, for dry runs.
+type Preview struct {
+	URL     string
+	Headers []string
+	Body    any
+}
+
+// StreamingProvider is implemented by providers that can deliver a text
+// response incrementally. onChunk receives each piece as it arrives and the
+// full response is returned at the end.
+type StreamingProvider interface {
+	Provider
+	GenerateStream(ctx context.Context, reqType string, userPrompt string, cfg *config.Config, onChunk func(string)) (string, error)
+}
+
+// NewProvider returns the provider selected by cfg.Provider. An empty name
+// falls back to the hosted diny service.
+func NewProvider(cfg *config.Config) (Provider, error) {
+	name := config.ProviderDiny
+	if cfg != nil && cfg.Provider.Name != "" {
+		name = cfg.Provider.Name
+	}
+
+	switch name {
+	case config.ProviderDiny:
+		return &cloudProvider{}, nil
+	case config.ProviderOllama:
+		return newOllamaProvider(cfg.Provider.Ollama), nil
+	case config.ProviderOpenAI:
+		return newOpenAIProvider(cfg.Provider.OpenAI), nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+}
@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Cmd is an exec.Cmd whose Run, Output and CombinedOutput are logged with
+// their arguments, duration and exit code.
+type Cmd struct {
+	*exec.Cmd
+}
+
+// Command is exec.Command with logging.
+func Command(name string, args ...string) *Cmd {
+	return &Cmd{Cmd: exec.Command(name, args...)}
+}
+
+func (c *Cmd) Run() error {
+	start := time.Now()
+	err := c.Cmd.Run()
+	c.record(start, err)
+	return err
+}
+
+func (c *Cmd) Output() ([]byte, error) {
+	start := time.Now()
+	out, err := c.Cmd.Output()
+	c.record(start, err)
+	return out, err
+}
+
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	start := time.Now()
+	out, err := c.Cmd.CombinedOutput()
+	c.record(start, err)
+	return out, err
+}
+
+func (c *Cmd) record(start time.Time, err error) {
+	args := []any{
+		"cmd", Scrub(strings.Join(c.Args, " ")),
+		"duration", time.Since(start).Round(time.Millisecond),
+	}
+	if c.Dir != "" {
+		args = append(args, "dir", c.Dir)
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		logger.Info("exec", append(args, "exit", 0)...)
+	case errors.As(err, &exitErr):
+		args = append(args, "exit", exitErr.ExitCode())
+		if stderr := strings.TrimSpace(string(exitErr.Stderr)); stderr != "" {
+			args = append(args, "stderr", Scrub(stderr))
+		}
+		logger.Info("exec", args...)
+	default:
+		logger.Warn("exec failed", append(args, "error", err.Error())...)
+	}
+}
@@ -0,0 +1,79 @@
+// Package logging records what diny does — git subprocesses, backend calls
+// and TUI state changes — so failures that the UI only summarises can be
+// looked at afterwards. Nothing is logged until Setup is called.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+var (
+	logger  = slog.New(slog.NewTextHandler(io.Discard, nil))
+	verbose bool
+)
+
+// Scrub removes secrets from text before it is written. The command layer
+// installs the configured redactor; until then text passes through as is.
+var Scrub = func(text string) string { return text }
+
+// Options configures Setup.
+type Options struct {
+	// Verbose logs at debug level and includes prompt and response bodies
+	// (with secrets still redacted). By default only their sizes are kept.
+	Verbose bool
+	// File is the log file. Empty uses diny.log in DefaultDir.
+	File string
+}
+
+// DefaultDir is where logs are kept unless --log-file says otherwise.
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "diny", "logs")
+}
+
+// Setup starts logging to a rotating file. The TUI owns the terminal, so
+// logs never go to stdout or stderr.
+func Setup(opts Options) error {
+	path := opts.File
+	if path == "" {
+		dir := DefaultDir()
+		if dir == "" {
+			return fmt.Errorf("cannot find home directory for logs")
+		}
+		path = filepath.Join(dir, "diny.log")
+	}
+
+	w, err := openRotating(path, maxLogBytes, keepLogs)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	level := slog.LevelInfo
+	if opts.Verbose {
+		level = slog.LevelDebug
+	}
+	verbose = opts.Verbose
+	logger = slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})).With("pid", os.Getpid())
+	return nil
+}
+
+func Debug(msg string, args ...any) { logger.Debug(msg, args...) }
+func Info(msg string, args ...any)  { logger.Info(msg, args...) }
+func Warn(msg string, args ...any)  { logger.Warn(msg, args...) }
+func Error(msg string, args ...any) { logger.Error(msg, args...) }
+
+// Body prepares a prompt, diff or response for the log: its size by
+// default, the scrubbed text itself with --verbose.
+func Body(text string) string {
+	if !verbose {
+		return fmt.Sprintf("<%d bytes>", len(text))
+	}
+	return Scrub(text)
+}
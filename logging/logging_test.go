@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFile_ShiftsOldLogs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "diny.log")
+	w, err := openRotating(path, 100, 2)
+	if err != nil {
+		t.Fatalf("openRotating: %v", err)
+	}
+
+	line := []byte(strings.Repeat("x", 60) + "\n")
+	for range 4 {
+		if _, err := w.Write(line); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	for _, name := range []string{"diny.log", "diny.log.1", "diny.log.2"} {
+		info, err := os.Stat(filepath.Join(filepath.Dir(path), name))
+		if err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+		if info.Size() > 100 {
+			t.Errorf("%s is %d bytes, over the limit", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 old logs to be kept")
+	}
+}
+
+func TestSetup_RecordsCommandsScrubbed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "diny.log")
+	if err := Setup(Options{File: path}); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	prevScrub := Scrub
+	Scrub = func(s string) string { return strings.ReplaceAll(s, "hunter2", "[REDACTED]") }
+	t.Cleanup(func() { Scrub = prevScrub })
+
+	if err := Command("go", "env", "hunter2").Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := Body("a diff body"); got != "<11 bytes>" {
+		t.Errorf("Body without --verbose = %q, want only the size", got)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	log := string(data)
+	if !strings.Contains(log, "msg=exec") || !strings.Contains(log, "exit=0") {
+		t.Errorf("command not recorded:\n%s", log)
+	}
+	if strings.Contains(log, "hunter2") {
+		t.Errorf("secret leaked into the log:\n%s", log)
+	}
+}
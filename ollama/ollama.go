@@ -3,55 +3,153 @@ package ollama
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
-)
+	"time"
 
-// const server = "http://127.0.0.1:11434"
-const server = "http://167.235.150.40"
+	"github.com/dinoDanic/diny/backend"
+	"github.com/dinoDanic/diny/config"
+)
 
-// const model = "qwen2.5:7b-instruct"
-// const model = "qwen2.5-coder:3b"
-// const model = "mistral:7b-instruct"
-const model = "llama3.2"
+type Options struct {
+	Temperature float64 `json:"temperature"`
+	NumCtx      int     `json:"num_ctx,omitempty"`
+}
 
 type GenerateRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
+	Model     string          `json:"model"`
+	System    string          `json:"system,omitempty"`
+	Prompt    string          `json:"prompt"`
+	Stream    bool            `json:"stream"`
+	Format    json.RawMessage `json:"format,omitempty"`
+	KeepAlive string          `json:"keep_alive,omitempty"`
+	Options   Options         `json:"options"`
 }
 
 type GenerateResponse struct {
 	Response string `json:"response"`
 	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Client talks to a local (or LAN) Ollama server using the settings from
+// the provider.ollama config block.
+type Client struct {
+	cfg  config.OllamaConfig
+	http *http.Client
+}
+
+func New(cfg config.OllamaConfig) *Client {
+	return &Client{
+		cfg: cfg,
+		// Local models can take a while to load on first use, so the
+		// timeout is deliberately generous.
+		http: &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// Model returns the configured model name.
+func (c *Client) Model() string {
+	return c.cfg.Model
+}
+
+// URL is the generate endpoint requests are posted to.
+func (c *Client) URL() string {
+	return strings.TrimRight(c.cfg.Endpoint, "/") + "/api/generate"
+}
+
+// Body returns the request body for a completion, as sent to URL.
+func (c *Client) Body(system, prompt string, format json.RawMessage, stream bool) GenerateRequest {
+	return GenerateRequest{
+		Model:     c.cfg.Model,
+		System:    system,
+		Prompt:    prompt,
+		Stream:    stream,
+		Format:    format,
+		KeepAlive: c.cfg.KeepAlive,
+		Options: Options{
+			Temperature: c.cfg.Temperature,
+			NumCtx:      c.cfg.ContextSize,
+		},
+	}
 }
 
-func MainStream(prompt string) (string, error) {
-	// fmt.Print("OLLAMA RECIVED")
-	// fmt.Print(prompt)
-	// fmt.Print("OLLAMA RECIVED END")
-	req := GenerateRequest{
-		Model:  model,
-		Prompt: prompt,
-		Stream: true,
+func (c *Client) newRequest(ctx context.Context, system, prompt string, format json.RawMessage, stream bool) (*http.Request, error) {
+	buf, err := json.Marshal(c.Body(system, prompt, format, stream))
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL(), bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// Generate runs a single non-streaming completion. format may be nil, the
+// literal "json", or a JSON schema the output must conform to.
+func (c *Client) Generate(ctx context.Context, system, prompt string, format json.RawMessage) (string, error) {
+	req, err := c.newRequest(ctx, system, prompt, format, false)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", backend.Transport(backendName, fmt.Errorf("call ollama at %s: %w", c.cfg.Endpoint, err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", backend.Transport(backendName, fmt.Errorf("read response: %w", err))
+	}
+
+	var out GenerateResponse
+	decodeErr := json.Unmarshal(body, &out)
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(resp, out.Error)
 	}
+	if decodeErr != nil {
+		return "", backend.Invalid(backendName, fmt.Errorf("decode response: %w", decodeErr))
+	}
+	if out.Error != "" {
+		return "", fmt.Errorf("ollama: %s", out.Error)
+	}
+
+	return out.Response, nil
+}
 
-	jsonData, err := json.Marshal(req)
+// GenerateStream runs a streaming completion, calling onChunk for every
+// token batch as it arrives. It returns the full concatenated response.
+func (c *Client) GenerateStream(ctx context.Context, system, prompt string, onChunk func(string)) (string, error) {
+	req, err := c.newRequest(ctx, system, prompt, nil, true)
 	if err != nil {
-		return "", fmt.Errorf("error marshaling JSON: %v", err)
+		return "", err
 	}
 
-	resp, err := http.Post(server+"/api/generate", "application/json", bytes.NewBuffer(jsonData))
+	resp, err := c.http.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("error calling Ollama: %v", err)
+		return "", backend.Transport(backendName, fmt.Errorf("call ollama at %s: %w", c.cfg.Endpoint, err))
 	}
 	defer resp.Body.Close()
 
-	var fullResponse strings.Builder
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var out GenerateResponse
+		_ = json.Unmarshal(body, &out)
+		return "", statusError(resp, out.Error)
+	}
+
+	var full strings.Builder
 	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -59,59 +157,38 @@ func MainStream(prompt string) (string, error) {
 			continue
 		}
 
-		var streamResp GenerateResponse
-		if err := json.Unmarshal([]byte(line), &streamResp); err != nil {
+		var chunk GenerateResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
 			continue // Skip invalid JSON lines
 		}
+		if chunk.Error != "" {
+			return "", fmt.Errorf("ollama: %s", chunk.Error)
+		}
 
-		// Print each chunk as it comes
-		fmt.Print(streamResp.Response)
-		fullResponse.WriteString(streamResp.Response)
+		if chunk.Response != "" {
+			full.WriteString(chunk.Response)
+			if onChunk != nil {
+				onChunk(chunk.Response)
+			}
+		}
 
-		if streamResp.Done {
+		if chunk.Done {
 			break
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("error reading stream: %v", err)
+		return "", backend.Transport(backendName, fmt.Errorf("read stream: %w", err))
 	}
 
-	return fullResponse.String(), nil
+	return full.String(), nil
 }
 
-func Main(prompt string) (string, error) {
-	req := GenerateRequest{
-		Model:  model,
-		Prompt: prompt,
-		Stream: false,
-	}
+const backendName = "ollama"
 
-	jsonData, err := json.Marshal(req)
-	if err != nil {
-		return "", fmt.Errorf("error marshaling JSON: %v", err)
+func statusError(resp *http.Response, msg string) error {
+	if msg != "" {
+		msg = "ollama: " + msg
 	}
-
-	fmt.Println("🐢 My tiny server is thinking hard, thanks for your patience!")
-
-	resp, err := http.Post(server+"/api/generate", "application/json", bytes.NewBuffer(jsonData))
-
-	if err != nil {
-		return "", fmt.Errorf("error calling Ollama: %v", err)
-	}
-
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("error reading response: %v", err)
-	}
-
-	var generateResp GenerateResponse
-	err = json.Unmarshal(body, &generateResp)
-	if err != nil {
-		return "", fmt.Errorf("error parsing response: %v", err)
-	}
-
-	return generateResp.Response, nil
+	return backend.Status(backendName, resp, msg)
 }
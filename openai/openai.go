@@ -0,0 +1,231 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dinoDanic/diny/backend"
+	"github.com/dinoDanic/diny/config"
+)
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type JSONSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict"`
+}
+
+type ResponseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+type ChatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []Message       `json:"messages"`
+	Temperature    float64         `json:"temperature"`
+	Stream         bool            `json:"stream"`
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+type choice struct {
+	Message Message `json:"message"`
+	Delta   Message `json:"delta"`
+}
+
+type apiError struct {
+	Message string `json:"message"`
+}
+
+type ChatResponse struct {
+	Choices []choice  `json:"choices"`
+	Error   *apiError `json:"error,omitempty"`
+}
+
+// Client speaks the OpenAI /chat/completions protocol, which vLLM, LM
+// Studio, llama.cpp server, Azure and most gateways implement.
+type Client struct {
+	cfg  config.OpenAIConfig
+	http *http.Client
+}
+
+func New(cfg config.OpenAIConfig) *Client {
+	return &Client{
+		cfg:  cfg,
+		http: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// Model returns the configured model name.
+func (c *Client) Model() string {
+	return c.cfg.Model
+}
+
+// apiKey resolves the key from the environment variable named in config.
+// An empty api_key_env means the server needs no authentication.
+func (c *Client) apiKey() (string, error) {
+	if c.cfg.APIKeyEnv == "" {
+		return "", nil
+	}
+	key := os.Getenv(c.cfg.APIKeyEnv)
+	if key == "" {
+		return "", fmt.Errorf("environment variable %s is not set", c.cfg.APIKeyEnv)
+	}
+	return key, nil
+}
+
+// URL is the chat-completions endpoint requests are posted to.
+func (c *Client) URL() string {
+	return strings.TrimRight(c.cfg.BaseURL, "/") + "/chat/completions"
+}
+
+// Body returns the request body for a completion, as sent to URL.
+func (c *Client) Body(messages []Message, format *ResponseFormat, stream bool) ChatRequest {
+	return ChatRequest{
+		Model:          c.cfg.Model,
+		Messages:       messages,
+		Temperature:    c.cfg.Temperature,
+		Stream:         stream,
+		ResponseFormat: format,
+	}
+}
+
+func (c *Client) newRequest(ctx context.Context, body ChatRequest) (*http.Request, error) {
+	key, err := c.apiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL(), bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	return req, nil
+}
+
+// Chat runs a single non-streaming completion and returns the assistant's
+// reply. format may be nil for free-form text.
+func (c *Client) Chat(ctx context.Context, messages []Message, format *ResponseFormat) (string, error) {
+	req, err := c.newRequest(ctx, c.Body(messages, format, false))
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", backend.Transport(c.cfg.BaseURL, fmt.Errorf("call %s: %w", c.cfg.BaseURL, err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", backend.Transport(c.cfg.BaseURL, fmt.Errorf("read response: %w", err))
+	}
+
+	var out ChatResponse
+	decodeErr := json.Unmarshal(body, &out)
+	if resp.StatusCode != http.StatusOK {
+		return "", c.statusError(resp, out.Error)
+	}
+	if decodeErr != nil {
+		return "", backend.Invalid(c.cfg.BaseURL, fmt.Errorf("decode response: %w", decodeErr))
+	}
+	if out.Error != nil {
+		return "", fmt.Errorf("openai: %s", out.Error.Message)
+	}
+	if len(out.Choices) == 0 {
+		return "", backend.Invalid(c.cfg.BaseURL, fmt.Errorf("no choices in response"))
+	}
+
+	return out.Choices[0].Message.Content, nil
+}
+
+// ChatStream runs a streaming completion over server-sent events, calling
+// onChunk for every content delta. It returns the full reply.
+func (c *Client) ChatStream(ctx context.Context, messages []Message, onChunk func(string)) (string, error) {
+	req, err := c.newRequest(ctx, c.Body(messages, nil, true))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", backend.Transport(c.cfg.BaseURL, fmt.Errorf("call %s: %w", c.cfg.BaseURL, err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var out ChatResponse
+		_ = json.Unmarshal(body, &out)
+		return "", c.statusError(resp, out.Error)
+	}
+
+	var full strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue // blank separators, comments and event: lines
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk ChatResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			continue
+		}
+		if chunk.Error != nil {
+			return "", fmt.Errorf("openai: %s", chunk.Error.Message)
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		delta := chunk.Choices[0].Delta.Content
+		full.WriteString(delta)
+		if onChunk != nil {
+			onChunk(delta)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", backend.Transport(c.cfg.BaseURL, fmt.Errorf("read stream: %w", err))
+	}
+
+	return full.String(), nil
+}
+
+func (c *Client) statusError(resp *http.Response, apiErr *apiError) error {
+	msg := ""
+	if apiErr != nil && apiErr.Message != "" {
+		msg = "openai: " + apiErr.Message
+	}
+	return backend.Status(c.cfg.BaseURL, resp, msg)
+}
@@ -0,0 +1,71 @@
+package privacy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/git"
+)
+
+// Identity is the set of identity fields attached to requests to the hosted
+// diny service and to feedback, after the privacy config has been applied.
+// Omitted fields are empty.
+type Identity struct {
+	Name     string
+	Email    string
+	RepoName string
+	System   string
+}
+
+// Collect reads the identity fields and applies cfg to each one.
+func Collect(cfg config.PrivacyConfig) Identity {
+	return Identity{
+		Name:     apply(cfg.Name, git.GetGitName()),
+		Email:    apply(cfg.Email, git.GetGitEmail()),
+		RepoName: apply(cfg.RepoName, git.GetRepoName()),
+		System:   apply(cfg.System, runtime.GOOS),
+	}
+}
+
+func apply(mode config.IdentityMode, value string) string {
+	switch mode {
+	case config.IdentityOmit:
+		return ""
+	case config.IdentityHash:
+		if value == "" {
+			return ""
+		}
+		return Hash(value)
+	}
+	return value
+}
+
+// Hash returns a stable pseudonym for value, so requests from the same
+// person or repository can still be grouped without revealing who it is.
+// Case and surrounding whitespace are ignored.
+func Hash(value string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(value))))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// TelemetryEnvVars are the environment variables that turn off feedback
+// prompts and feedback uploads.
+var TelemetryEnvVars = []string{"DO_NOT_TRACK", "DINY_NO_TELEMETRY"}
+
+// TelemetryDisabled reports whether the user opted out of telemetry through
+// the environment. Any value other than "", "0" or "false" counts, per the
+// DO_NOT_TRACK convention. The second result names the variable.
+func TelemetryDisabled() (bool, string) {
+	for _, env := range TelemetryEnvVars {
+		switch strings.ToLower(strings.TrimSpace(os.Getenv(env))) {
+		case "", "0", "false":
+			continue
+		}
+		return true, env
+	}
+	return false, ""
+}
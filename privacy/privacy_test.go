@@ -0,0 +1,46 @@
+package privacy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dinoDanic/diny/config"
+)
+
+func TestApply(t *testing.T) {
+	if got := apply("", "dino"); got != "dino" {
+		t.Errorf("empty mode: got %q, want value unchanged", got)
+	}
+	if got := apply(config.IdentityOmit, "dino"); got != "" {
+		t.Errorf("omit: got %q, want empty", got)
+	}
+	got := apply(config.IdentityHash, "Dino@Example.com ")
+	if !strings.HasPrefix(got, "sha256:") || strings.Contains(got, "dino") {
+		t.Errorf("hash: got %q", got)
+	}
+	if got != Hash("dino@example.com") {
+		t.Error("hash should ignore case and surrounding whitespace")
+	}
+	if got := apply(config.IdentityHash, ""); got != "" {
+		t.Errorf("hash of empty value: got %q, want empty", got)
+	}
+}
+
+func TestTelemetryDisabled(t *testing.T) {
+	cases := []struct {
+		dnt, diny string
+		want      bool
+	}{
+		{"", "", false},
+		{"0", "false", false},
+		{"1", "", true},
+		{"", "true", true},
+	}
+	for _, tc := range cases {
+		t.Setenv("DO_NOT_TRACK", tc.dnt)
+		t.Setenv("DINY_NO_TELEMETRY", tc.diny)
+		if got, _ := TelemetryDisabled(); got != tc.want {
+			t.Errorf("DO_NOT_TRACK=%q DINY_NO_TELEMETRY=%q: got %v, want %v", tc.dnt, tc.diny, got, tc.want)
+		}
+	}
+}
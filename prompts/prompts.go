@@ -3,14 +3,12 @@ package prompts
 import (
 	"math/rand"
 	"os"
-	"runtime"
 	"strconv"
 
 	"github.com/dinoDanic/diny/config"
 	"github.com/dinoDanic/diny/feedback"
-	"github.com/dinoDanic/diny/git"
+	"github.com/dinoDanic/diny/privacy"
 	tuiprompts "github.com/dinoDanic/diny/tui/prompts"
-	"github.com/dinoDanic/diny/version"
 	"github.com/mattn/go-isatty"
 )
 
@@ -24,6 +22,11 @@ func MaybeShow(cfg *config.Config) {
 		return
 	}
 
+	// Gate: DO_NOT_TRACK / DINY_NO_TELEMETRY.
+	if off, _ := privacy.TelemetryDisabled(); off {
+		return
+	}
+
 	// Gate: non-interactive environments (CI, piped stdout).
 	if !isInteractive() {
 		return
@@ -83,15 +86,7 @@ func showStarPrompt(state *State, cfg *config.Config) {
 		return
 	}
 
-	feedback.Send(feedback.Payload{
-		Type:     "star",
-		Value:    outcome,
-		Email:    git.GetGitEmail(),
-		Name:     git.GetGitName(),
-		Version:  version.Get(),
-		System:   runtime.GOOS,
-		RepoName: git.GetRepoName(),
-	})
+	feedback.Send(feedback.NewPayload("star", outcome, cfg))
 
 	switch outcome {
 	case "starred":
@@ -107,15 +102,7 @@ func showRatingPrompt(state *State, cfg *config.Config) {
 
 	// Only POST + thank if the user rated (1-3), not on dismiss (0) or cancel (-1).
 	if value >= 1 && value <= 3 {
-		feedback.Send(feedback.Payload{
-			Type:     "rating",
-			Value:    strconv.Itoa(value),
-			Email:    git.GetGitEmail(),
-			Name:     git.GetGitName(),
-			Version:  version.Get(),
-			System:   runtime.GOOS,
-			RepoName: git.GetRepoName(),
-		})
+		feedback.Send(feedback.NewPayload("rating", strconv.Itoa(value), cfg))
 		tuiprompts.PrintThanks("Thanks for the feedback!")
 	}
 }
@@ -125,15 +112,7 @@ func showFeedbackPrompt(state *State, cfg *config.Config) {
 	_ = SaveState(state)
 
 	if text != "" {
-		feedback.Send(feedback.Payload{
-			Type:     "feedback",
-			Value:    text,
-			Email:    git.GetGitEmail(),
-			Name:     git.GetGitName(),
-			Version:  version.Get(),
-			System:   runtime.GOOS,
-			RepoName: git.GetRepoName(),
-		})
+		feedback.Send(feedback.NewPayload("feedback", text, cfg))
 		tuiprompts.PrintThanks("Thanks for the feedback!")
 	}
 }
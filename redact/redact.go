@@ -0,0 +1,218 @@
+package redact
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/dinoDanic/diny/config"
+)
+
+// Redaction replaces likely secrets in a diff with placeholders such as
+// [REDACTED:github-token] before the text is sent to any backend. Headers
+// are left alone so file names stay intact.
+
+type detector struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+var builtin = []detector{
+	{"aws-access-key", regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA)[0-9A-Z]{16}\b`)},
+	{"github-token", regexp.MustCompile(`\b(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,255}\b`)},
+	{"github-token", regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,255}\b`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+}
+
+// entropyCandidate matches base64/base64url-looking runs long enough to be
+// keys. Shorter runs are mostly identifiers.
+var entropyCandidate = regexp.MustCompile(`[A-Za-z0-9+/_=-]{20,}`)
+
+// maxEntropyThreshold is the bits/char a long run must reach to count as
+// random. Short runs cannot reach it, so the bar scales with length.
+const maxEntropyThreshold = 4.3
+
+// FileCount is the number of redactions made in one file.
+type FileCount struct {
+	File  string
+	Count int
+}
+
+// Report lists redactions per file in the order the files appear in the
+// diff. Text outside any file section is reported under "".
+type Report []FileCount
+
+// Total returns the number of redactions across all files.
+func (r Report) Total() int {
+	n := 0
+	for _, fc := range r {
+		n += fc.Count
+	}
+	return n
+}
+
+// Apply redacts text according to cfg and reports what it replaced. Invalid
+// user patterns are skipped; config validation rejects them up front.
+func Apply(text string, cfg config.RedactConfig) (string, Report) {
+	if !cfg.Enabled || text == "" {
+		return text, nil
+	}
+
+	var custom []*regexp.Regexp
+	for _, p := range cfg.Patterns {
+		if re, err := regexp.Compile(p); err == nil {
+			custom = append(custom, re)
+		}
+	}
+
+	var (
+		file    string
+		inHunk  bool
+		inKey   bool
+		counts  = map[string]int{}
+		order   []string
+		lines   = strings.Split(text, "\n")
+		changed bool
+	)
+	count := func(n int) {
+		if n == 0 {
+			return
+		}
+		if _, ok := counts[file]; !ok {
+			order = append(order, file)
+		}
+		counts[file] += n
+		changed = true
+	}
+
+	for i, line := range lines {
+		if strings.HasPrefix(line, "diff --git ") {
+			file = diffPath(line)
+			inHunk, inKey = false, false
+			continue
+		}
+		if strings.HasPrefix(line, "@@") {
+			inHunk = true
+			continue
+		}
+		if file != "" && !inHunk {
+			// index, mode, rename and ---/+++ header lines
+			continue
+		}
+
+		// Keep the +/-/space marker so the diff still reads as a diff.
+		prefix, body := "", line
+		if inHunk && line != "" && strings.ContainsRune("+- ", rune(line[0])) {
+			prefix, body = line[:1], line[1:]
+		}
+
+		if inKey {
+			if strings.Contains(body, "-----END") {
+				inKey = false
+			}
+			lines[i] = prefix
+			continue
+		}
+		if strings.Contains(body, "-----BEGIN") && strings.Contains(body, "PRIVATE KEY") {
+			inKey = !strings.Contains(body, "-----END")
+			lines[i] = prefix + placeholder("private-key")
+			count(1)
+			continue
+		}
+
+		n := 0
+		for _, d := range builtin {
+			body, n = replace(d.re, body, d.kind, n)
+		}
+		for _, re := range custom {
+			body, n = replace(re, body, "custom", n)
+		}
+		if !skipEntropy(file) {
+			body = entropyCandidate.ReplaceAllStringFunc(body, func(s string) string {
+				if !looksRandom(s) {
+					return s
+				}
+				n++
+				return placeholder("high-entropy")
+			})
+		}
+		if n > 0 {
+			lines[i] = prefix + body
+			count(n)
+		}
+	}
+
+	if !changed {
+		return text, nil
+	}
+	report := make(Report, 0, len(order))
+	for _, f := range order {
+		report = append(report, FileCount{File: f, Count: counts[f]})
+	}
+	return strings.Join(lines, "\n"), report
+}
+
+func placeholder(kind string) string {
+	return "[REDACTED:" + kind + "]"
+}
+
+func replace(re *regexp.Regexp, s, kind string, n int) (string, int) {
+	out := re.ReplaceAllStringFunc(s, func(string) string {
+		n++
+		return placeholder(kind)
+	})
+	return out, n
+}
+
+// diffPath extracts the b/ side of a "diff --git a/x b/x" header.
+func diffPath(header string) string {
+	rest := strings.TrimPrefix(header, "diff --git ")
+	if i := strings.LastIndex(rest, " b/"); i >= 0 {
+		return rest[i+3:]
+	}
+	return rest
+}
+
+// skipEntropy reports files made of checksums by design, where every line
+// would look like a secret.
+func skipEntropy(file string) bool {
+	base := path.Base(file)
+	return base == "go.sum" || strings.HasSuffix(base, ".lock") || strings.HasSuffix(base, "-lock.json") ||
+		strings.HasSuffix(base, "-lock.yaml")
+}
+
+// looksRandom reports whether s has the character mix and entropy of a
+// generated key rather than an identifier or path.
+func looksRandom(s string) bool {
+	var upper, lower, digit bool
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	if !(upper && lower && digit) {
+		return false
+	}
+	threshold := math.Min(maxEntropyThreshold, 0.92*math.Log2(float64(len(s))))
+	return shannon(s) >= threshold
+}
+
+func shannon(s string) float64 {
+	freq := map[rune]int{}
+	for _, r := range s {
+		freq[r]++
+	}
+	n := float64(len(s))
+	var h float64
+	for _, c := range freq {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
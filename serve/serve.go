@@ -0,0 +1,152 @@
+package serve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dinoDanic/diny/backend"
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/feedback"
+	"github.com/dinoDanic/diny/groq"
+)
+
+// maxBodyBytes bounds a single request. Diffs are the bulk of it.
+const maxBodyBytes = 8 << 20
+
+// Options configures the handler behind `diny serve`.
+type Options struct {
+	// Config selects the model backend requests are forwarded to. Its
+	// provider must not be diny, or the server would call itself.
+	Config *config.Config
+	// Feedback receives one JSON line per /api/feedback call. nil drops it.
+	Feedback io.Writer
+	// Logger records one line per request. nil uses the standard logger.
+	Logger *log.Logger
+}
+
+// The wire format mirrors the hosted service so the CLI needs no changes.
+type reply struct {
+	Error *string    `json:"error,omitempty"`
+	Data  *replyData `json:"data,omitempty"`
+}
+
+type replyData struct {
+	Message string            `json:"message"`
+	Groups  []groq.SplitGroup `json:"groups"`
+}
+
+type handler struct {
+	opts Options
+	log  *log.Logger
+	mu   sync.Mutex // serialises writes to opts.Feedback
+}
+
+// Handler returns the /api/requests and /api/feedback endpoints.
+func Handler(opts Options) (http.Handler, error) {
+	if opts.Config == nil {
+		return nil, fmt.Errorf("no config")
+	}
+	switch opts.Config.Provider.Name {
+	case config.ProviderOllama, config.ProviderOpenAI:
+	default:
+		return nil, fmt.Errorf("diny serve needs provider.name set to ollama or openai, got %q", opts.Config.Provider.Name)
+	}
+
+	h := &handler{opts: opts, log: opts.Logger}
+	if h.log == nil {
+		h.log = log.Default()
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/requests", h.requests)
+	mux.HandleFunc("POST /api/feedback", h.feedback)
+	return mux, nil
+}
+
+func (h *handler) requests(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	var req groq.Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+		return
+	}
+	switch req.Type {
+	case "commit", "split", "timeline", "summary":
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown request type %q", req.Type))
+		return
+	}
+
+	msg, groups, err := groq.Answer(r.Context(), req, h.opts.Config)
+	if err != nil {
+		status := errorStatus(err)
+		h.log.Printf("%s %s from %s: %d %v (%s)", req.Type, req.Version, r.RemoteAddr, status, err, time.Since(start).Round(time.Millisecond))
+		var be *backend.Error
+		if errors.As(err, &be) && be.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(be.RetryAfter.Seconds())))
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+
+	h.log.Printf("%s %s from %s: ok (%s)", req.Type, req.Version, r.RemoteAddr, time.Since(start).Round(time.Millisecond))
+	writeJSON(w, http.StatusOK, reply{Data: &replyData{Message: msg, Groups: groups}})
+}
+
+func (h *handler) feedback(w http.ResponseWriter, r *http.Request) {
+	var p feedback.Payload
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&p); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid feedback: %v", err))
+		return
+	}
+
+	h.log.Printf("feedback %s from %s", p.Type, r.RemoteAddr)
+	if h.opts.Feedback != nil {
+		line, err := json.Marshal(p)
+		if err == nil {
+			h.mu.Lock()
+			_, err = h.opts.Feedback.Write(append(line, '\n'))
+			h.mu.Unlock()
+		}
+		if err != nil {
+			h.log.Printf("failed to record feedback: %v", err)
+		}
+	}
+	writeJSON(w, http.StatusOK, reply{})
+}
+
+// errorStatus maps a backend failure onto the status the CLI already
+// understands: 429 and 5xx are retried, anything else is shown as is.
+func errorStatus(err error) int {
+	var be *backend.Error
+	if !errors.As(err, &be) {
+		return http.StatusUnprocessableEntity
+	}
+	switch be.Kind {
+	case backend.KindRateLimited:
+		return http.StatusTooManyRequests
+	case backend.KindTimeout:
+		return http.StatusGatewayTimeout
+	case backend.KindUnreachable, backend.KindServer, backend.KindInvalidResponse:
+		return http.StatusBadGateway
+	}
+	return http.StatusUnprocessableEntity
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, reply{Error: &msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
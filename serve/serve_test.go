@@ -0,0 +1,115 @@
+package serve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/groq"
+	"github.com/dinoDanic/diny/server"
+)
+
+// fakeModel answers chat completions with a fixed message and records the
+// system prompt it was given.
+func fakeModel(t *testing.T, system *string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Messages) > 0 {
+			*system = req.Messages[0].Content
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"feat: add login"}}]}`))
+	}))
+}
+
+func TestHandler_ForwardsToModel(t *testing.T) {
+	var system string
+	model := fakeModel(t, &system)
+	defer model.Close()
+
+	h, err := Handler(Options{
+		Config: &config.Config{Provider: config.ProviderConfig{
+			Name:   config.ProviderOpenAI,
+			OpenAI: config.OpenAIConfig{BaseURL: model.URL, Model: "test"},
+		}},
+		Logger: log.New(&bytes.Buffer{}, "", 0),
+	})
+	if err != nil {
+		t.Fatalf("Handler: %v", err)
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	prev := server.ServerConfig.BaseURL
+	server.ServerConfig.BaseURL = srv.URL
+	defer func() { server.ServerConfig.BaseURL = prev }()
+
+	// The CLI side is unchanged: the diny provider talks to our server.
+	client := &config.Config{Commit: config.CommitConfig{Conventional: true, Tone: config.Casual, Length: config.Short}}
+	msg, err := groq.CreateCommitMessageWithGroq(context.Background(), "diff --git a/x b/x", client)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if msg != "feat: add login" {
+		t.Fatalf("got %q", msg)
+	}
+	if !strings.Contains(system, "type(scope): subject") {
+		t.Fatalf("client commit settings were not used for the prompt:\n%s", system)
+	}
+
+	// The CLI sends large-diff chunks as timelines, which the hosted service
+	// knows; a summary sent directly gets its own system prompt.
+	resp, err := http.Post(srv.URL+"/api/requests", "application/json", strings.NewReader(`{"type":"summary","userPrompt":"diff --git a/x b/x"}`))
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("summary: got status %d", resp.StatusCode)
+	}
+	if !strings.Contains(system, "per-file notes") {
+		t.Fatalf("summary used the wrong system prompt:\n%s", system)
+	}
+}
+
+func TestHandler_RejectsBadRequests(t *testing.T) {
+	var feedback bytes.Buffer
+	h, err := Handler(Options{
+		Config:   &config.Config{Provider: config.ProviderConfig{Name: config.ProviderOllama}},
+		Feedback: &feedback,
+		Logger:   log.New(&bytes.Buffer{}, "", 0),
+	})
+	if err != nil {
+		t.Fatalf("Handler: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/requests", strings.NewReader(`{"type":"poem"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown type: got status %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/feedback", strings.NewReader(`{"type":"rating","value":"3"}`)))
+	if rec.Code != http.StatusOK || !strings.Contains(feedback.String(), `"value":"3"`) {
+		t.Fatalf("feedback: got status %d, recorded %q", rec.Code, feedback.String())
+	}
+}
+
+func TestHandler_RefusesDinyProvider(t *testing.T) {
+	if _, err := Handler(Options{Config: &config.Config{Provider: config.ProviderConfig{Name: config.ProviderDiny}}}); err == nil {
+		t.Fatal("expected an error: serving with the diny provider would forward to itself")
+	}
+}
@@ -1,10 +1,31 @@
 package server
 
+import (
+	"os"
+	"strings"
+)
+
+// DefaultBaseURL is the hosted diny service.
+const DefaultBaseURL = "https://diny-cli.vercel.app"
+
 type ServerConfigS struct {
 	BaseURL string `json:"base_url"`
 }
 
 var ServerConfig = ServerConfigS{
 	// BaseURL: "http://localhost:3578",
-	BaseURL: "https://diny-cli.vercel.app",
+	BaseURL: DefaultBaseURL,
+}
+
+// Configure selects the server the diny provider and feedback talk to.
+// DINY_SERVER_URL wins over configURL; with neither set the hosted service
+// is used.
+func Configure(configURL string) {
+	url := DefaultBaseURL
+	if env := strings.TrimSpace(os.Getenv("DINY_SERVER_URL")); env != "" {
+		url = env
+	} else if configURL != "" {
+		url = configURL
+	}
+	ServerConfig.BaseURL = strings.TrimRight(url, "/")
 }
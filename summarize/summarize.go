@@ -0,0 +1,320 @@
+// Package summarize shrinks diffs that do not fit in a single request. The
+// diff is cut into chunks along file and hunk boundaries, each chunk is
+// summarised on its own (map), and the summaries are combined, summarising
+// again while they are still too large (reduce). The result replaces the
+// diff in the final commit, split or changelog request.
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dinoDanic/diny/cache"
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/groq"
+)
+
+const (
+	// parallel bounds the chunk requests in flight at once.
+	parallel = 4
+	// maxReduceRounds bounds how often summaries are summarised again.
+	maxReduceRounds = 3
+
+	hunkTruncated = "\n... (hunk truncated)\n"
+)
+
+// generate answers one free-form request. Tests replace it.
+var generate = groq.CreateSummaryWithGroq
+
+var (
+	memoMu sync.Mutex
+	memo   = map[string]string{}
+)
+
+// TooLarge reports whether diff is over the configured budget and would be
+// summarised by Condense.
+func TooLarge(diff string, cfg *config.Config) bool {
+	return cfg.LargeDiff.Budget > 0 && len(diff) > cfg.LargeDiff.Budget
+}
+
+// Condense returns diff unchanged when it fits cfg.LargeDiff.Budget, and a
+// digest of per-file summaries otherwise. onProgress, if set, is called as
+// each chunk finishes with the number done and the total for the current
+// round. Digests are remembered for the process and in the response cache,
+// so regenerating from the same diff does not summarise it again.
+func Condense(ctx context.Context, diff string, cfg *config.Config, onProgress func(done, total int)) (string, error) {
+	if !TooLarge(diff, cfg) {
+		return diff, nil
+	}
+	if digest, ok := Cached(diff, cfg); ok {
+		return digest, nil
+	}
+
+	budget := cfg.LargeDiff.Budget
+	size := chunkBudget(budget)
+	headers := fileHeaders(diff)
+	summaries, err := summarise(ctx, Chunks(diff, size), mapPrompt, cfg, onProgress)
+	if err != nil {
+		return "", err
+	}
+	combined := strings.Join(summaries, "\n\n")
+
+	for round := 0; round < maxReduceRounds && len(combined) > budget; round++ {
+		summaries, err = summarise(ctx, Chunks(combined, size), reducePrompt, cfg, onProgress)
+		if err != nil {
+			return "", err
+		}
+		combined = strings.Join(summaries, "\n\n")
+	}
+	if len(combined) > budget {
+		combined = combined[:budget] + "\n... (summaries truncated)"
+	}
+
+	digest := digestHeader(len(diff), headers) + combined
+	key := digestKey(diff, cfg)
+	remember(key, digest)
+	store, _ := cache.Open(cfg.Cache)
+	_ = store.Put(key, digest)
+	return digest, nil
+}
+
+// Cached returns the digest Condense already made for diff, if any.
+func Cached(diff string, cfg *config.Config) (string, bool) {
+	key := digestKey(diff, cfg)
+	memoMu.Lock()
+	digest, ok := memo[key]
+	memoMu.Unlock()
+	if ok {
+		return digest, true
+	}
+	store, _ := cache.Open(cfg.Cache)
+	if store.Get(key, &digest) && digest != "" {
+		remember(key, digest)
+		return digest, true
+	}
+	return "", false
+}
+
+// Parts returns how many summary requests the first round of Condense
+// makes for diff, or 0 when it fits the budget.
+func Parts(diff string, cfg *config.Config) int {
+	if !TooLarge(diff, cfg) {
+		return 0
+	}
+	return len(Chunks(diff, chunkBudget(cfg.LargeDiff.Budget)))
+}
+
+func digestKey(diff string, cfg *config.Config) string {
+	return cache.Key("digest", diff, strconv.Itoa(cfg.LargeDiff.Budget), cfg.Provider.Identity())
+}
+
+// chunkBudget leaves room for the instructions wrapped around every chunk.
+func chunkBudget(budget int) int {
+	return max(budget-len(mapPrompt("")), budget/2)
+}
+
+func remember(key, digest string) {
+	memoMu.Lock()
+	memo[key] = digest
+	memoMu.Unlock()
+}
+
+// summarise runs prompt over every chunk, at most parallel at a time, and
+// returns the answers in chunk order. The first failure cancels the rest.
+func summarise(ctx context.Context, chunks []string, prompt func(string) string, cfg *config.Config, onProgress func(done, total int)) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	out := make([]string, len(chunks))
+	sem := make(chan struct{}, parallel)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		done     int
+		firstErr error
+	)
+	if onProgress != nil {
+		onProgress(0, len(chunks))
+	}
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			summary, err := generate(ctx, prompt(chunk), cfg)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to summarise part %d of %d: %w", i+1, len(chunks), err)
+					cancel()
+				}
+				return
+			}
+			out[i] = strings.TrimSpace(summary)
+			done++
+			if onProgress != nil {
+				onProgress(done, len(chunks))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func mapPrompt(chunk string) string {
+	return `Summarise this part of a git diff for someone who will write the commit message without seeing the diff.
+For every file in it, write a section starting with "File: <path> (added, modified, deleted or renamed)" followed by 1-5 bullets on what changed and why it matters. Name the functions, types, flags and config keys involved.
+Reply with the sections only.
+
+` + chunk
+}
+
+func reducePrompt(chunk string) string {
+	return `Shorten these per-file summaries of a git diff. Keep every "File: <path> (...)" line exactly as written, and merge or drop bullets so each file has at most 2.
+Reply with the sections only.
+
+` + chunk
+}
+
+// digestHeader explains what the model is looking at and keeps the exact
+// file headers, so split plans can still name every path.
+func digestHeader(size int, headers []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The diff is too large to send whole (%d characters in %d files), so it was summarised per file first.\n", size, len(headers))
+	b.WriteString("Changed files:\n")
+	for _, h := range headers {
+		b.WriteString(h)
+		b.WriteString("\n")
+	}
+	b.WriteString("\nSummaries:\n")
+	return b.String()
+}
+
+func fileHeaders(diff string) []string {
+	var out []string
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// Chunks cuts text into pieces of at most budget characters. Diffs are cut
+// between files, and a file that is too large on its own is cut between
+// hunks with its header repeated on every piece; a single hunk that is
+// still too large is truncated. Other text is cut between paragraphs.
+func Chunks(text string, budget int) []string {
+	var sections []string
+	if strings.Contains(text, "diff --git ") {
+		for _, file := range splitBefore(text, "diff --git ") {
+			if len(file) <= budget {
+				sections = append(sections, file)
+				continue
+			}
+			sections = append(sections, splitFile(file, budget)...)
+		}
+	} else {
+		for _, p := range strings.SplitAfter(text, "\n\n") {
+			sections = append(sections, truncate(p, budget))
+		}
+	}
+	return pack(sections, budget)
+}
+
+// splitFile cuts one file's diff between hunks, repeating the header.
+func splitFile(file string, budget int) []string {
+	parts := splitBefore(file, "@@ ")
+	header, hunks := parts[0], parts[1:]
+	if len(hunks) == 0 {
+		// Binary or header-only: nothing worth keeping beyond the header.
+		return []string{truncate(file, budget)}
+	}
+
+	var out []string
+	var cur strings.Builder
+	for _, h := range hunks {
+		if len(header)+len(h) > budget {
+			h = truncate(h, budget-len(header))
+		}
+		if cur.Len() > 0 && cur.Len()+len(h) > budget {
+			out = append(out, cur.String())
+			cur.Reset()
+		}
+		if cur.Len() == 0 {
+			cur.WriteString(header)
+		}
+		cur.WriteString(h)
+	}
+	if cur.Len() > 0 {
+		out = append(out, cur.String())
+	}
+	return out
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	cut := n - len(hunkTruncated)
+	if cut < 0 {
+		cut = 0
+	}
+	return s[:cut] + hunkTruncated
+}
+
+// splitBefore splits text before every line starting with prefix. Text
+// before the first such line, if any, is the first element.
+func splitBefore(text, prefix string) []string {
+	var out []string
+	start := 0
+	for i := 0; i < len(text); {
+		if strings.HasPrefix(text[i:], prefix) && i > start {
+			out = append(out, text[start:i])
+			start = i
+		}
+		next := strings.IndexByte(text[i:], '\n')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return append(out, text[start:])
+}
+
+// pack greedily joins consecutive sections while they fit in budget.
+func pack(sections []string, budget int) []string {
+	var out []string
+	var cur strings.Builder
+	for _, s := range sections {
+		if s == "" {
+			continue
+		}
+		if cur.Len() > 0 && cur.Len()+len(s) > budget {
+			out = append(out, cur.String())
+			cur.Reset()
+		}
+		cur.WriteString(s)
+	}
+	if cur.Len() > 0 {
+		out = append(out, cur.String())
+	}
+	return out
+}
@@ -0,0 +1,146 @@
+package summarize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dinoDanic/diny/config"
+)
+
+func fileDiff(path string, hunks, linesPerHunk int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", path, path, path, path)
+	for h := range hunks {
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", h*100, linesPerHunk, h*100, linesPerHunk)
+		for l := range linesPerHunk {
+			fmt.Fprintf(&b, "+line %d of hunk %d\n", l, h)
+		}
+	}
+	return b.String()
+}
+
+func TestChunks_PacksWholeFiles(t *testing.T) {
+	diff := fileDiff("a.go", 1, 3) + fileDiff("b.go", 1, 3) + fileDiff("c.go", 1, 3)
+
+	chunks := Chunks(diff, len(diff))
+	if len(chunks) != 1 || chunks[0] != diff {
+		t.Fatalf("a diff within budget should be one chunk, got %d", len(chunks))
+	}
+
+	chunks = Chunks(diff, len(fileDiff("a.go", 1, 3))*2)
+	if len(chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2", len(chunks))
+	}
+	if strings.Join(chunks, "") != diff {
+		t.Fatal("chunks should reassemble into the original diff")
+	}
+}
+
+func TestChunks_SplitsLargeFileByHunk(t *testing.T) {
+	diff := fileDiff("big.go", 6, 20)
+	budget := len(diff) / 3
+
+	chunks := Chunks(diff, budget)
+	if len(chunks) < 3 {
+		t.Fatalf("got %d chunks, want the file split across at least 3", len(chunks))
+	}
+	for i, c := range chunks {
+		if len(c) > budget {
+			t.Errorf("chunk %d is %d characters, budget %d", i, len(c), budget)
+		}
+		if !strings.HasPrefix(c, "diff --git a/big.go b/big.go\n") {
+			t.Errorf("chunk %d lost the file header:\n%s", i, c)
+		}
+	}
+}
+
+func TestChunks_TruncatesOversizedHunk(t *testing.T) {
+	diff := fileDiff("huge.go", 1, 500)
+
+	chunks := Chunks(diff, 1000)
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(chunks))
+	}
+	if len(chunks[0]) > 1000 || !strings.Contains(chunks[0], "(hunk truncated)") {
+		t.Fatalf("expected a truncated chunk within budget, got %d characters", len(chunks[0]))
+	}
+}
+
+func withGenerator(t *testing.T, fn func(ctx context.Context, prompt string, cfg *config.Config) (string, error)) {
+	t.Helper()
+	prev := generate
+	generate = fn
+	t.Cleanup(func() { generate = prev })
+}
+
+func TestCondense_UnderBudget(t *testing.T) {
+	withGenerator(t, func(context.Context, string, *config.Config) (string, error) {
+		t.Fatal("no request expected for a small diff")
+		return "", nil
+	})
+	diff := fileDiff("a.go", 1, 3)
+	cfg := &config.Config{LargeDiff: config.LargeDiffConfig{Budget: 10000}}
+
+	got, err := Condense(context.Background(), diff, cfg, nil)
+	if err != nil || got != diff {
+		t.Fatalf("got %q, %v; want the diff unchanged", got, err)
+	}
+}
+
+func TestCondense_SummarisesEachChunk(t *testing.T) {
+	var calls atomic.Int32
+	withGenerator(t, func(_ context.Context, prompt string, _ *config.Config) (string, error) {
+		calls.Add(1)
+		i := strings.Index(prompt, "diff --git a/")
+		path := strings.Fields(prompt[i+len("diff --git a/"):])[0]
+		return "File: " + path + " (modified)\n- changed lines", nil
+	})
+
+	var diff string
+	for i := range 8 {
+		diff += fileDiff(fmt.Sprintf("pkg/f%d.go", i), 2, 40)
+	}
+	cfg := &config.Config{LargeDiff: config.LargeDiffConfig{Budget: 3000}}
+
+	var lastDone, lastTotal int
+	got, err := Condense(context.Background(), diff, cfg, func(done, total int) { lastDone, lastTotal = done, total })
+	if err != nil {
+		t.Fatalf("Condense: %v", err)
+	}
+	if n := int(calls.Load()); n < 2 || n != lastTotal || lastDone != lastTotal {
+		t.Fatalf("made %d requests, progress ended at %d/%d", n, lastDone, lastTotal)
+	}
+	if len(got) >= len(diff) {
+		t.Fatalf("digest is not smaller than the diff: %d >= %d", len(got), len(diff))
+	}
+	for i := range 8 {
+		header := fmt.Sprintf("diff --git a/pkg/f%d.go b/pkg/f%d.go", i, i)
+		if !strings.Contains(got, header) {
+			t.Errorf("digest is missing %q", header)
+		}
+	}
+
+	// The same diff is answered from memory.
+	before := calls.Load()
+	if again, err := Condense(context.Background(), diff, cfg, nil); err != nil || again != got || calls.Load() != before {
+		t.Fatalf("expected the digest to be reused without new requests")
+	}
+}
+
+func TestCondense_PropagatesErrors(t *testing.T) {
+	boom := errors.New("backend down")
+	withGenerator(t, func(context.Context, string, *config.Config) (string, error) {
+		return "", boom
+	})
+
+	diff := fileDiff("x.go", 4, 60) + fileDiff("y.go", 4, 60)
+	cfg := &config.Config{LargeDiff: config.LargeDiffConfig{Budget: 2000}}
+
+	if _, err := Condense(context.Background(), diff, cfg, nil); !errors.Is(err, boom) {
+		t.Fatalf("got %v, want the backend error", err)
+	}
+}
@@ -2,8 +2,9 @@ package app
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strings"
 	"time"
@@ -13,6 +14,9 @@ import (
 	"github.com/dinoDanic/diny/commit"
 	"github.com/dinoDanic/diny/config"
 	"github.com/dinoDanic/diny/git"
+	"github.com/dinoDanic/diny/logging"
+	"github.com/dinoDanic/diny/redact"
+	"github.com/dinoDanic/diny/summarize"
 )
 
 type commitProgressMsg struct {
@@ -61,6 +65,42 @@ func waitForCommitLine(ch <-chan string) tea.Cmd {
 	}
 }
 
+// streamChunkMsg carries a piece of generated text. slot is -1 for the main
+// commit message and the variant index otherwise. ch identifies the stream
+// so chunks from a superseded stream can be drained without being applied.
+// progress, when set, replaces the loader text instead of adding a chunk.
+type streamChunkMsg struct {
+	ch       <-chan streamChunkMsg
+	slot     int
+	chunk    string
+	progress string
+}
+
+func waitForStream(ch <-chan streamChunkMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// streamTo returns an onChunk callback that forwards text into ch.
+func streamTo(ch chan streamChunkMsg, slot int) func(string) {
+	return func(chunk string) {
+		ch <- streamChunkMsg{ch: ch, slot: slot, chunk: chunk}
+	}
+}
+
+// condense summarises diff first when it is over the size budget,
+// reporting each finished chunk on ch.
+func condense(ctx context.Context, diff string, cfg *config.Config, ch chan streamChunkMsg) (string, error) {
+	return summarize.Condense(ctx, diff, cfg, func(done, total int) {
+		ch <- streamChunkMsg{ch: ch, progress: fmt.Sprintf("large diff — summarised %d/%d parts...", done, total)}
+	})
+}
+
 func loadRepoInfo() tea.Cmd {
 	return func() tea.Msg {
 		repoName := git.GetRepoName()
@@ -90,8 +130,10 @@ func startWelcomeTimer() tea.Cmd {
 	})
 }
 
-func loadDiffAndGenerate(cfg *config.Config) tea.Cmd {
+func loadDiffAndGenerate(ctx context.Context, cfg *config.Config, streamCh chan streamChunkMsg) tea.Cmd {
 	return func() tea.Msg {
+		defer close(streamCh)
+
 		diff, err := git.GetGitDiff()
 		if err != nil {
 			return errMsg{err: fmt.Errorf("failed to get git diff: %w", err)}
@@ -100,18 +142,111 @@ func loadDiffAndGenerate(cfg *config.Config) tea.Cmd {
 			return errMsg{err: fmt.Errorf("no diff found for staged changes")}
 		}
 
-		msg, err := commit.CreateCommitMessage(diff, cfg)
+		// The backend layer redacts on its own; this only feeds the banner.
+		_, redactions := redact.Apply(diff, cfg.Redact)
+
+		request := sentRequest{reqType: "commit", prompt: diff}
+		if msg, ok := commit.CachedCommitMessage(diff, cfg); ok {
+			request.cached = true
+			return diffAndCommitMsg{diff: diff, commitMessage: msg, cached: true, redactions: redactions, request: request}
+		}
+
+		prompt, err := condense(ctx, diff, cfg, streamCh)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to summarise large diff: %w", err)}
+		}
+
+		msg, err := commit.CreateCommitMessageStream(ctx, prompt, cfg, streamTo(streamCh, -1))
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to generate commit message: %w", err)}
+		}
+		commit.CacheCommitMessage(diff, cfg, msg)
+		return diffAndCommitMsg{diff: diff, commitMessage: msg, redactions: redactions, request: request}
+	}
+}
+
+// loadAmendAndGenerate refines HEAD's message from the diff an amend would
+// commit, HEAD~1 to the index. Results are not cached: the same diff can
+// come with a different HEAD message.
+func loadAmendAndGenerate(ctx context.Context, cfg *config.Config, streamCh chan streamChunkMsg) tea.Cmd {
+	return func() tea.Msg {
+		defer close(streamCh)
+
+		diff, err := git.GetAmendDiff()
+		if err != nil {
+			return errMsg{err: err}
+		}
+		head, err := git.GetHeadMessage()
+		if err != nil {
+			return errMsg{err: err}
+		}
+		upstream, err := git.GetHeadUpstream()
+		if err != nil {
+			logging.Warn("amend: failed to check upstream", "error", err.Error())
+		}
+		// Trailers stay out of the prompt and are carried over separately.
+		body, trailers := commit.SplitTrailers(head)
+		target := &amendTarget{message: head, trailers: trailers, upstream: upstream}
+
+		_, redactions := redact.Apply(diff, cfg.Redact)
+		request := sentRequest{reqType: "commit", prompt: commit.AmendPrompt(diff, body)}
+
+		digest, err := condense(ctx, diff, cfg, streamCh)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to summarise large diff: %w", err)}
+		}
+
+		msg, err := commit.CreateCommitMessageStream(ctx, commit.AmendPrompt(digest, body), cfg, streamTo(streamCh, -1))
+		if ctx.Err() != nil {
+			return nil
+		}
 		if err != nil {
 			return errMsg{err: fmt.Errorf("failed to generate commit message: %w", err)}
 		}
-		return diffAndCommitMsg{diff: diff, commitMessage: msg}
+		return diffAndCommitMsg{diff: diff, commitMessage: msg, redactions: redactions, request: request, amend: target}
+	}
+}
+
+// doGenerateOffline builds the message from file statuses and the diffstat
+// without contacting the backend.
+func doGenerateOffline(cfg *config.Config, streamCh chan streamChunkMsg) tea.Cmd {
+	return func() tea.Msg {
+		defer close(streamCh)
+
+		diff, err := git.GetGitDiff()
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to get git diff: %w", err)}
+		}
+		if diff == "" {
+			return errMsg{err: fmt.Errorf("no diff found for staged changes")}
+		}
+
+		msg, err := commit.CreateOfflineCommitMessage(cfg)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to build offline message: %w", err)}
+		}
+		return diffAndCommitMsg{diff: diff, commitMessage: msg, request: sentRequest{offline: true}}
 	}
 }
 
-func doCommit(message string, push bool, noVerify bool, amend bool, cfg *config.Config, progressCh chan string) tea.Cmd {
+func doCommit(message string, trailers []string, push bool, noVerify bool, amend bool, cfg *config.Config, progressCh chan string) tea.Cmd {
 	return func() tea.Msg {
 		defer close(progressCh)
 
+		message, err := commit.WithTrailers(message, trailers)
+		if err != nil {
+			return errMsg{err: err}
+		}
+
 		// Build args
 		var args []string
 		if amend {
@@ -123,8 +258,14 @@ func doCommit(message string, push bool, noVerify bool, amend bool, cfg *config.
 			args = append([]string{args[0], "--no-verify"}, args[1:]...)
 		}
 
+		kind := "commit"
+		if amend {
+			kind = "amend"
+		}
+		journal := commit.BeginJournal(kind)
+
 		lw := &lineWriter{ch: progressCh}
-		cmd := exec.Command("git", args...)
+		cmd := logging.Command("git", args...)
 		cmd.Stdout = lw
 		cmd.Stderr = lw
 		if err := cmd.Run(); err != nil {
@@ -132,13 +273,14 @@ func doCommit(message string, push bool, noVerify bool, amend bool, cfg *config.
 		}
 
 		if amend {
+			journal.Record([]string{message}, false)
 			return commitDoneMsg{hash: "", push: false}
 		}
 
 		// hash after commit
 		var hash string
 		if cfg != nil && cfg.Commit.HashAfterCommit {
-			if out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output(); err == nil {
+			if out, err := logging.Command("git", "rev-parse", "--short", "HEAD").Output(); err == nil {
 				hash = strings.TrimSpace(string(out))
 				_ = clipboard.WriteAll(hash)
 			}
@@ -147,52 +289,95 @@ func doCommit(message string, push bool, noVerify bool, amend bool, cfg *config.
 		// optional push
 		if push {
 			pushLw := &lineWriter{ch: progressCh}
-			pushCmd := exec.Command("git", "push")
+			pushCmd := logging.Command("git", "push")
 			pushCmd.Stdout = pushLw
 			pushCmd.Stderr = pushLw
 			if err := pushCmd.Run(); err != nil {
+				journal.Record([]string{message}, false)
 				return errMsg{err: fmt.Errorf("committed but push failed: %s", strings.TrimSpace(string(pushLw.all)))}
 			}
 		}
+		journal.Record([]string{message}, push)
 
 		return commitDoneMsg{hash: hash, push: push}
 	}
 }
 
-func doRegenerate(diff string, cfg *config.Config, previousMessages []string, current string) tea.Cmd {
+func doRegenerate(ctx context.Context, diff string, cfg *config.Config, previousMessages []string, current string, streamCh chan streamChunkMsg) tea.Cmd {
 	return func() tea.Msg {
-		modifiedDiff := diff
-		allPrev := append(previousMessages, current)
-		if len(allPrev) > 0 {
-			modifiedDiff += "\n\nPrevious commit messages that were not satisfactory:\n"
-			for i, msg := range allPrev {
-				modifiedDiff += fmt.Sprintf("%d. %s\n", i+1, msg)
-			}
-			modifiedDiff += "\nPlease generate a different commit message that avoids the style and approach of the previous ones."
+		defer close(streamCh)
+
+		digest, err := condense(ctx, diff, cfg, streamCh)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to summarise large diff: %w", err)}
 		}
+		allPrev := append(previousMessages, current)
 
-		msg, err := commit.CreateCommitMessage(modifiedDiff, cfg)
+		msg, err := commit.CreateCommitMessageStream(ctx, commit.RegeneratePrompt(digest, allPrev), cfg, streamTo(streamCh, -1))
+		if ctx.Err() != nil {
+			return nil
+		}
 		if err != nil {
 			return errMsg{err: fmt.Errorf("failed to regenerate: %w", err)}
 		}
-		return diffAndCommitMsg{diff: diff, commitMessage: msg}
+		// Remember the latest answer so a re-run starts from it.
+		commit.CacheCommitMessage(diff, cfg, msg)
+		_, redactions := redact.Apply(diff, cfg.Redact)
+		request := sentRequest{reqType: "commit", prompt: commit.RegeneratePrompt(diff, allPrev)}
+		return diffAndCommitMsg{diff: diff, commitMessage: msg, redactions: redactions, request: request}
 	}
 }
 
-func doFeedback(diff string, cfg *config.Config, current string, feedback string) tea.Cmd {
+func doFeedback(ctx context.Context, diff string, cfg *config.Config, current string, feedback string, streamCh chan streamChunkMsg) tea.Cmd {
 	return func() tea.Msg {
-		modifiedDiff := diff + fmt.Sprintf("\n\nCurrent commit message:\n%s\n\nUser feedback: %s\n\nPlease generate a new commit message that addresses the user's feedback.", current, feedback)
+		defer close(streamCh)
+
+		digest, err := condense(ctx, diff, cfg, streamCh)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to summarise large diff: %w", err)}
+		}
 
-		msg, err := commit.CreateCommitMessage(modifiedDiff, cfg)
+		msg, err := commit.CreateCommitMessageStream(ctx, commit.FeedbackPrompt(digest, current, feedback), cfg, streamTo(streamCh, -1))
+		if ctx.Err() != nil {
+			return nil
+		}
 		if err != nil {
 			return errMsg{err: fmt.Errorf("failed to refine: %w", err)}
 		}
-		return diffAndCommitMsg{diff: diff, commitMessage: msg}
+		commit.CacheCommitMessage(diff, cfg, msg)
+		_, redactions := redact.Apply(diff, cfg.Redact)
+		request := sentRequest{reqType: "commit", prompt: commit.FeedbackPrompt(diff, current, feedback)}
+		return diffAndCommitMsg{diff: diff, commitMessage: msg, redactions: redactions, request: request}
+	}
+}
+
+// doDescribeRequest renders req for the request view without sending it.
+// For a cached result it is the request that would be sent.
+func doDescribeRequest(diff string, req sentRequest, cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		if req.offline {
+			return requestViewMsg{text: "This message was built offline from the staged file list.\nNothing was sent."}
+		}
+		text, err := commit.DryRun(req.reqType, diff, req.prompt, cfg, req.extras)
+		if err != nil {
+			return errMsg{err: err}
+		}
+		return requestViewMsg{text: text, cached: req.cached}
 	}
 }
 
-func doSaveDraft(message string) tea.Cmd {
+func doSaveDraft(message string, trailers []string) tea.Cmd {
 	return func() tea.Msg {
+		message, err := commit.WithTrailers(message, trailers)
+		if err != nil {
+			return errMsg{err: err}
+		}
 		if err := commit.SaveDraft(message); err != nil {
 			return errMsg{err: fmt.Errorf("failed to save draft: %w", err)}
 		}
@@ -213,7 +398,7 @@ func loadUnstagedFiles() tea.Cmd {
 func doStageFiles(paths []string) tea.Cmd {
 	return func() tea.Msg {
 		args := append([]string{"add", "--"}, paths...)
-		cmd := exec.Command("git", args...)
+		cmd := logging.Command("git", args...)
 		if out, err := cmd.CombinedOutput(); err != nil {
 			return errMsg{err: fmt.Errorf("git add failed: %s", strings.TrimSpace(string(out)))}
 		}
@@ -225,47 +410,60 @@ func doStageFiles(paths []string) tea.Cmd {
 	}
 }
 
-func doGenerateVariants(diff string, cfg *config.Config, previousMessages []string, current string) tea.Cmd {
+func doGenerateVariants(ctx context.Context, diff string, cfg *config.Config, previousMessages []string, current string, streamCh chan streamChunkMsg) tea.Cmd {
 	return func() tea.Msg {
-		const n = 3
+		defer close(streamCh)
+
+		const n = variantCount
 		type result struct {
-			msg string
-			err error
+			slot int
+			msg  string
+			err  error
 		}
 
-		modifiedDiff := diff
-		allPrev := append(previousMessages, current)
-		modifiedDiff += "\n\nPrevious commit messages that were not satisfactory:\n"
-		for i, m := range allPrev {
-			modifiedDiff += fmt.Sprintf("%d. %s\n", i+1, m)
+		digest, err := condense(ctx, diff, cfg, streamCh)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to summarise large diff: %w", err)}
 		}
-		modifiedDiff += "\nPlease generate a different commit message that avoids the style and approach of the previous ones."
+		allPrev := append(previousMessages, current)
+		prompt := commit.RegeneratePrompt(digest, allPrev)
 
 		ch := make(chan result, n)
-		for range n {
+		for i := range n {
 			go func() {
-				msg, err := commit.CreateCommitMessage(modifiedDiff, cfg)
-				ch <- result{msg, err}
+				msg, err := commit.CreateCommitMessageStream(ctx, prompt, cfg, streamTo(streamCh, i))
+				ch <- result{i, msg, err}
 			}()
 		}
 
-		var variants []string
+		variants := make([]string, n)
+		var ok []string
 		var lastErr error
 		for range n {
 			r := <-ch
 			if r.err != nil {
 				lastErr = r.err
 			} else {
-				variants = append(variants, r.msg)
+				variants[r.slot] = r.msg
+				ok = append(ok, r.msg)
 			}
 		}
-		if len(variants) == 0 {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if len(ok) == 0 {
 			return errMsg{err: fmt.Errorf("all variants failed: %w", lastErr)}
 		}
-		for len(variants) < n {
-			variants = append(variants, variants[0])
+		for i := range variants {
+			if variants[i] == "" {
+				variants[i] = ok[0]
+			}
 		}
-		return variantsReadyMsg{variants: variants}
+		request := sentRequest{reqType: "commit", prompt: commit.RegeneratePrompt(diff, allPrev)}
+		return variantsReadyMsg{variants: variants, request: request}
 	}
 }
 
@@ -296,11 +494,11 @@ func doApplyFilePicker(entries []fileEntry) tea.Cmd {
 			if e.wantStaged == e.currentStaged {
 				continue
 			}
-			var cmd *exec.Cmd
+			var cmd *logging.Cmd
 			if e.wantStaged {
-				cmd = exec.Command("git", "add", "--", e.path)
+				cmd = logging.Command("git", "add", "--", e.path)
 			} else {
-				cmd = exec.Command("git", "restore", "--staged", "--", e.path)
+				cmd = logging.Command("git", "restore", "--staged", "--", e.path)
 			}
 			if out, err := cmd.CombinedOutput(); err != nil {
 				return errMsg{err: fmt.Errorf("git operation failed: %s", strings.TrimSpace(string(out)))}
@@ -314,8 +512,12 @@ func doApplyFilePicker(entries []fileEntry) tea.Cmd {
 	}
 }
 
-func doCopy(message string) tea.Cmd {
+func doCopy(message string, trailers []string) tea.Cmd {
 	return func() tea.Msg {
+		message, err := commit.WithTrailers(message, trailers)
+		if err != nil {
+			return errMsg{err: err}
+		}
 		if err := clipboard.WriteAll(message); err != nil {
 			return errMsg{err: fmt.Errorf("failed to copy: %w", err)}
 		}
@@ -323,67 +525,76 @@ func doCopy(message string) tea.Cmd {
 	}
 }
 
-func loadSplitPlan(diff string, cfg *config.Config, staged []git.StagedFile, previousPlans [][]commit.SplitGroup, feedback string) tea.Cmd {
+func loadSplitPlan(ctx context.Context, diff string, cfg *config.Config, staged []git.StagedFile, previousPlans [][]commit.SplitGroup, feedback string, streamCh chan streamChunkMsg) tea.Cmd {
 	return func() tea.Msg {
+		defer close(streamCh)
+
+		request := sentRequest{reqType: "split", prompt: diff}
 		var extras *commit.SplitRequestExtras
 		if len(previousPlans) > 0 || feedback != "" {
 			extras = &commit.SplitRequestExtras{
 				PreviousPlans: previousPlans,
 				Feedback:      feedback,
 			}
+			request.extras = extras
+		} else if plan, ok := commit.CachedSplitPlan(diff, cfg); ok {
+			if err := commit.ValidatePlan(plan, staged, diff); err == nil {
+				request.cached = true
+				return splitPlanReadyMsg{plan: plan, cached: true, request: request}
+			}
+		}
+		prompt, err := condense(ctx, diff, cfg, streamCh)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to summarise large diff: %w", err)}
+		}
+		plan, err := commit.CreateSplitPlan(ctx, prompt, cfg, extras)
+		if ctx.Err() != nil {
+			return nil
 		}
-		plan, err := commit.CreateSplitPlan(diff, cfg, extras)
 		if err != nil {
 			return errMsg{err: fmt.Errorf("failed to generate split plan: %w", err)}
 		}
 		plan = commit.NormalizePlan(plan)
-		if err := commit.ValidatePlan(plan, staged); err != nil {
+		if err := commit.ValidatePlan(plan, staged, diff); err != nil {
 			return errMsg{err: fmt.Errorf("invalid split plan: %w", err)}
 		}
-		return splitPlanReadyMsg{plan: plan}
+		commit.CacheSplitPlan(diff, cfg, plan)
+		return splitPlanReadyMsg{plan: plan, request: request}
 	}
 }
 
-func doExecuteSplit(plan []commit.SplitGroup, noVerify bool, push bool, cfg *config.Config) tea.Cmd {
+// doExecuteSplit commits plan group by group via commit.SplitRun. A run
+// that stops early is saved so `diny commit --continue-split` can resume it.
+// Cancelling ctx stops before the next group.
+func doExecuteSplit(ctx context.Context, plan []commit.SplitGroup, diff string, trailers []string, noVerify bool, push bool, cfg *config.Config) tea.Cmd {
 	return func() tea.Msg {
-		if out, err := exec.Command("git", "reset").CombinedOutput(); err != nil {
-			return errMsg{err: fmt.Errorf("git reset failed: %s", strings.TrimSpace(string(out)))}
-		}
-
-		hashes := make([]string, 0, len(plan))
-		for i, g := range plan {
-			addArgs := append([]string{"add", "--"}, g.Files...)
-			if out, err := exec.Command("git", addArgs...).CombinedOutput(); err != nil {
-				return splitCommitFailureMsg{
-					committedHashes: hashes,
-					failedIndex:     i,
-					failedStderr:    strings.TrimSpace(string(out)),
-					failedFiles:     g.Files,
-					remainingFiles:  filesFromGroups(plan[i+1:]),
-				}
-			}
+		run, err := commit.StartSplit(plan, diff, noVerify, push)
+		if err != nil {
+			return errMsg{err: err}
+		}
+		run.Trailers = trailers
 
-			args := []string{"commit", "-m", g.Message}
-			if noVerify {
-				args = []string{"commit", "--no-verify", "-m", g.Message}
+		if err := run.Run(ctx); err != nil {
+			failure := splitCommitFailureMsg{
+				committedHashes: run.Hashes,
+				failedIndex:     run.Next,
+				snapshot:        run.Snapshot,
 			}
-			commitCmd := exec.Command("git", args...)
-			if out, err := commitCmd.CombinedOutput(); err != nil {
-				return splitCommitFailureMsg{
-					committedHashes: hashes,
-					failedIndex:     i,
-					failedStderr:    strings.TrimSpace(string(out)),
-					failedFiles:     g.Files,
-					remainingFiles:  filesFromGroups(plan[i+1:]),
-				}
-			}
-
-			hash := ""
-			if out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output(); err == nil {
-				hash = strings.TrimSpace(string(out))
+			var splitErr *commit.SplitError
+			if errors.As(err, &splitErr) {
+				failure.failedStderr = splitErr.Output
+				failure.failedFiles = commit.GroupFiles(plan[run.Next])
+				failure.remainingFiles = filesFromGroups(plan[run.Next+1:])
+			} else {
+				failure.aborted = true
+				failure.remainingFiles = filesFromGroups(plan[run.Next:])
 			}
-			hashes = append(hashes, hash)
+			return failure
 		}
+		hashes := run.Hashes
 
 		// Copy final hash to clipboard if config requests it.
 		if cfg != nil && cfg.Commit.HashAfterCommit && len(hashes) > 0 {
@@ -394,20 +605,32 @@ func doExecuteSplit(plan []commit.SplitGroup, noVerify bool, push bool, cfg *con
 
 		// Optional push after the final commit.
 		if push {
-			pushCmd := exec.Command("git", "push")
+			pushCmd := logging.Command("git", "push")
 			if out, err := pushCmd.CombinedOutput(); err != nil {
 				return errMsg{err: fmt.Errorf("committed %d group(s) but push failed: %s", len(hashes), strings.TrimSpace(string(out)))}
 			}
+			commit.MarkPushed(run.Head)
 		}
 
 		return splitCommitDoneMsg{hashes: hashes}
 	}
 }
 
+// doRestoreIndex puts the index back the way it was before a split. The
+// saved split is dropped too, since the index no longer matches it.
+func doRestoreIndex(snapshot string) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.ReadTree(snapshot); err != nil {
+			return indexRestoredMsg{err: err}
+		}
+		return indexRestoredMsg{err: commit.ClearSplitState()}
+	}
+}
+
 func filesFromGroups(groups []commit.SplitGroup) []string {
 	var out []string
 	for _, g := range groups {
-		out = append(out, g.Files...)
+		out = append(out, commit.GroupFiles(g)...)
 	}
 	return out
 }
@@ -1,12 +1,17 @@
 package app
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/dinoDanic/diny/commit"
 	"github.com/dinoDanic/diny/config"
 	"github.com/dinoDanic/diny/git"
+	"github.com/dinoDanic/diny/redact"
 	"github.com/dinoDanic/diny/tui/loader"
 )
 
@@ -33,8 +38,42 @@ const (
 	stateSplitSuccess
 	stateSplitFailure
 	stateSplitFeedback
+	stateRequestView
+	stateCoAuthorPicker
 )
 
+var stateNames = map[state]string{
+	stateWelcome:         "welcome",
+	stateGenerating:      "generating",
+	stateReady:           "ready",
+	stateFeedback:        "feedback",
+	stateEditing:         "editing",
+	stateHelp:            "help",
+	stateCommitting:      "committing",
+	stateSuccess:         "success",
+	stateNoStaged:        "no-staged",
+	stateError:           "error",
+	stateVariantPicking:  "variant-picking",
+	stateDiffView:        "diff-view",
+	stateTypePicker:      "type-picker",
+	stateFilePicker:      "file-picker",
+	stateSplitGenerating: "split-generating",
+	stateSplitPlan:       "split-plan",
+	stateSplitCommitting: "split-committing",
+	stateSplitSuccess:    "split-success",
+	stateSplitFailure:    "split-failure",
+	stateSplitFeedback:   "split-feedback",
+	stateRequestView:     "request-view",
+	stateCoAuthorPicker:  "co-author-picker",
+}
+
+func (s state) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("state(%d)", int(s))
+}
+
 type fileEntry struct {
 	path          string
 	status        string
@@ -59,6 +98,33 @@ type welcomeTimerDoneMsg struct{}
 type diffAndCommitMsg struct {
 	diff          string
 	commitMessage string
+	cached        bool
+	redactions    redact.Report
+	request       sentRequest
+	amend         *amendTarget // set when the message refines HEAD's
+}
+
+// amendTarget is the commit an amend replaces.
+type amendTarget struct {
+	message  string
+	trailers []string // HEAD's trailers, kept out of the prompt
+	upstream string   // upstream branch HEAD is already on, if any
+}
+
+// sentRequest is the request behind what is on screen, kept so the P key
+// can show it. prompt is built from the full diff; large diffs are shown
+// with their summaries in place.
+type sentRequest struct {
+	reqType string
+	prompt  string
+	extras  *commit.SplitRequestExtras
+	offline bool
+	cached  bool // the result came from the cache, so nothing was sent
+}
+
+type requestViewMsg struct {
+	text   string
+	cached bool
 }
 
 type commitDoneMsg struct {
@@ -88,6 +154,7 @@ type splitEditorFinishedMsg struct {
 
 type variantsReadyMsg struct {
 	variants []string
+	request  sentRequest
 }
 
 type allFilesMsg struct {
@@ -99,7 +166,9 @@ type filePickerDoneMsg struct {
 }
 
 type splitPlanReadyMsg struct {
-	plan []commit.SplitGroup
+	plan    []commit.SplitGroup
+	cached  bool
+	request sentRequest
 }
 
 type splitCommitDoneMsg struct {
@@ -114,6 +183,13 @@ type splitCommitFailureMsg struct {
 	failedStderr    string         // stderr from git for the failing group
 	remainingFiles  []string       // files from groups that never ran
 	failedFiles     []string       // files from the failing group (left staged)
+	snapshot        string         // tree of the index before the split
+	aborted         bool           // stopped by the user before failedIndex ran
+}
+
+// indexRestoredMsg reports the result of putting the pre-split index back.
+type indexRestoredMsg struct {
+	err error
 }
 
 // Model
@@ -127,6 +203,7 @@ type model struct {
 	// Context (loaded async in Init)
 	repoName    string
 	branchName  string
+	issueKey    string // from the branch name (commit.issue)
 	gitUserName string
 	stagedFiles []git.StagedFile
 
@@ -136,6 +213,21 @@ type model struct {
 	previousMessages []string
 	commitProgress   string
 	commitOutputCh   <-chan string
+	cached           bool          // commitMessage came from the on-disk cache
+	redactions       redact.Report // secrets replaced before the diff was sent
+	request          sentRequest   // what produced commitMessage
+	variantsRequest  sentRequest   // what produced the variants on offer
+	splitRequest     sentRequest   // what produced splitPlan
+	requestReturn    state         // view to go back to from stateRequestView
+	requestCached    bool          // the request view is for a cached result
+
+	// Streaming generation — text arrives chunk by chunk while generating
+	streamCh   <-chan streamChunkMsg
+	streamText string
+
+	// In-flight generation — cancelled when the user leaves the state
+	cancel context.CancelFunc
+	retry  func(model) (model, tea.Cmd) // re-runs the last generation from stateError
 
 	// Components
 	loader   loader.Model
@@ -159,6 +251,8 @@ type model struct {
 	pendingPush     bool
 	pendingNoVerify bool
 	pendingAmend    bool
+	amend           *amendTarget // HEAD being amended, once loaded
+	amendConfirmed  bool         // user accepted amending a pushed HEAD
 
 	// History navigation ([/] keys)
 	messageHistoryIdx int    // -1 = current; >=0 = index into previousMessages
@@ -170,12 +264,18 @@ type model struct {
 	fileSelected  []bool
 
 	// Variant picker (stateVariantPicking)
-	variants      []string
-	variantCursor int
+	variants          []string
+	variantCursor     int
+	variantsStreaming bool // slots are still filling in
 
 	// Type picker (stateTypePicker)
 	typeCursor int
 
+	// Trailers added to the message on its way out, kept apart from it so
+	// they are never sent and survive regenerating (commit.trailers).
+	trailers       []string
+	coAuthorCursor int
+
 	// File picker (stateFilePicker)
 	fileEntries      []fileEntry
 	filePickerCursor int
@@ -187,10 +287,14 @@ type model struct {
 	splitHashes   []string
 	splitFailure  *splitCommitFailureMsg
 	splitPushed   bool
-
-	// Split move mode — reassigning a file from its current group to another
-	splitMoveMode     bool // cursor is on a file in splitCursor's group
-	splitMoveFileIdx  int  // index into splitPlan[splitCursor].Files
+	splitAborting bool                    // esc pressed while committing; stops before the next group
+	splitRestored bool                    // the pre-split index was put back after a failure
+	splitCached   bool                    // splitPlan came from the on-disk cache
+	splitDiff     map[string]git.FileDiff // the staged diff by path, for hunk entries
+
+	// Split move mode — reassigning a file or hunk from its current group to another
+	splitMoveMode     bool // cursor is on an entry in splitCursor's group
+	splitMoveFileIdx  int  // index into splitItems(splitPlan[splitCursor])
 	splitMovePickDest bool // second step: choose destination group (>9 groups or via enter)
 	splitMoveDestIdx  int  // cursor into destination group list
 
@@ -202,6 +306,44 @@ type model struct {
 	cliNoVerify bool
 	cliPush     bool
 	cliPrint    bool
+
+	// Offline mode — messages are built locally from the staged file list
+	offline bool
+}
+
+const variantCount = 3
+
+// openStream resets the streamed text and returns a fresh chunk channel
+// that becomes the model's active stream.
+func (m *model) openStream() chan streamChunkMsg {
+	ch := make(chan streamChunkMsg, 64)
+	m.streamCh = ch
+	m.streamText = ""
+	return ch
+}
+
+// startRequest cancels any in-flight generation and returns a fresh context
+// for the next one.
+func (m *model) startRequest() context.Context {
+	// Only cancel here; the stream is owned by openStream.
+	if m.cancel != nil {
+		m.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	return ctx
+}
+
+// cancelRequest aborts the in-flight generation, if any, and detaches its
+// stream so late chunks are drained without being shown.
+func (m *model) cancelRequest() {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	m.retry = nil
+	m.streamCh = nil
+	m.streamText = ""
 }
 
 func newModel(cfg *config.Config, version string, opts Options) model {
@@ -222,5 +364,8 @@ func newModel(cfg *config.Config, version string, opts Options) model {
 		cliNoVerify:       opts.NoVerify,
 		cliPush:           opts.Push,
 		cliPrint:          opts.Print,
+		offline:           opts.Offline,
+		pendingAmend:      opts.Amend,
+		trailers:          commit.ConfigTrailers(cfg),
 	}
 }
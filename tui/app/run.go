@@ -13,6 +13,8 @@ type Options struct {
 	NoVerify bool
 	Push     bool
 	Print    bool
+	Offline  bool
+	Amend    bool
 }
 
 // RunResult holds the outcome of the TUI session.
@@ -88,6 +88,12 @@ func statusErrorStyle() lipgloss.Style {
 		Foreground(t.ErrorForeground)
 }
 
+func warningStyle() lipgloss.Style {
+	t := ui.GetCurrentTheme()
+	return lipgloss.NewStyle().
+		Foreground(t.WarningForeground)
+}
+
 func spinnerStyle() lipgloss.Style {
 	t := ui.GetCurrentTheme()
 	return lipgloss.NewStyle().
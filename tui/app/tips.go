@@ -6,7 +6,7 @@ var tips = []string{
 	"use [ and ] to browse previously generated messages in this session",
 	"press t to force a conventional commit type (feat, fix, docs...)",
 	"press L to cycle message length: short → normal → long",
-	"press A to regenerate from HEAD diff and amend on commit",
+	"press A to amend HEAD with a message refined from the whole change",
 	"press M to toggle emoji on/off for this session",
 	"press s to save as a draft — useful with lazygit",
 	"press d to view the full staged diff before committing",
@@ -1,18 +1,22 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dinoDanic/diny/backend"
 	"github.com/dinoDanic/diny/commit"
 	"github.com/dinoDanic/diny/config"
 	"github.com/dinoDanic/diny/git"
+	"github.com/dinoDanic/diny/logging"
 	"github.com/dinoDanic/diny/tui/loader"
 )
 
@@ -26,6 +30,7 @@ func clonePlan(plan []commit.SplitGroup) []commit.SplitGroup {
 			Type:    g.Type,
 			Message: g.Message,
 			Files:   files,
+			Hunks:   append([]commit.HunkRef(nil), g.Hunks...),
 		}
 	}
 	return out
@@ -42,7 +47,20 @@ func (m model) Init() tea.Cmd {
 	)
 }
 
+// Update logs state transitions around update, which does the work.
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if n, ok := next.(model); ok && n.state != m.state {
+		args := []any{"tui", "commit", "from", m.state, "to", n.state}
+		if n.state == stateError && n.err != nil {
+			args = append(args, "error", logging.Scrub(n.err.Error()))
+		}
+		logging.Info("state", args...)
+	}
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -55,6 +73,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case repoInfoMsg:
 		m.repoName = msg.repoName
 		m.branchName = msg.branchName
+		m.issueKey = commit.IssueKey(m.cfg.Commit.Issue, msg.branchName)
 		m.gitUserName = msg.gitUserName
 		m.repoLoaded = true
 		return m.checkWelcomeDone()
@@ -75,8 +94,27 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.checkWelcomeDone()
 
 	case diffAndCommitMsg:
+		if m.state != stateGenerating {
+			// Finished just as it was cancelled.
+			return m, nil
+		}
+		m.cancel = nil
+		m.retry = nil
+		m.streamCh = nil
+		m.streamText = ""
 		m.diff = msg.diff
-		m.commitMessage = msg.commitMessage
+		m.commitMessage = commit.ApplyIssue(msg.commitMessage, m.cfg.Commit.Issue, m.issueKey)
+		m.cached = msg.cached
+		m.redactions = msg.redactions
+		m.request = msg.request
+		if msg.amend != nil {
+			m.amend = msg.amend
+			for _, t := range msg.amend.trailers {
+				if !slices.Contains(m.trailers, t) {
+					m.trailers = append(slices.Clone(m.trailers), t)
+				}
+			}
+		}
 		m.messageHistoryIdx = -1
 		m.savedMessage = ""
 		m.state = stateReady
@@ -91,13 +129,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case filePickerDoneMsg:
 		m.stagedFiles = msg.files
+		if m.pendingAmend {
+			return m.generate(func(ctx context.Context, ch chan streamChunkMsg) tea.Cmd {
+				return loadAmendAndGenerate(ctx, m.cfg, ch)
+			})
+		}
 		if len(m.stagedFiles) == 0 {
 			m.state = stateNoStaged
 			return m, loadUnstagedFiles()
 		}
-		m.state = stateGenerating
-		m.loader = loader.New(loader.GeneratingMessages)
-		return m, tea.Batch(m.loader.Tick, loadDiffAndGenerate(m.cfg))
+		return m.generate(func(ctx context.Context, ch chan streamChunkMsg) tea.Cmd {
+			return loadDiffAndGenerate(ctx, m.cfg, ch)
+		})
 
 	case commitDoneMsg:
 		m.state = stateSuccess
@@ -129,12 +172,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.state = stateError
 		m.err = msg.err
+		m.cancel = nil
+		m.streamCh = nil
+		m.variantsStreaming = false
 		return m, nil
 
-	case editorFinishedMsg:
-		if msg.newMessage != "" && msg.newMessage != m.commitMessage {
-			m.commitMessage = msg.newMessage
+	case requestViewMsg:
+		if m.state != m.requestReturn {
+			return m, nil
 		}
+		vp := viewport.New(m.width-6, m.height-8)
+		vp.SetContent(msg.text)
+		m.viewport = vp
+		m.requestCached = msg.cached
+		m.state = stateRequestView
+		return m, nil
+
+	case editorFinishedMsg:
+		m = m.acceptEdit(msg.newMessage)
 		m.state = stateReady
 		return m, nil
 
@@ -145,17 +200,57 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case variantsReadyMsg:
+		if !m.variantsStreaming {
+			return m, nil
+		}
+		m.cancel = nil
+		m.retry = nil
 		m.variants = msg.variants
-		m.variantCursor = 0
+		for i, v := range m.variants {
+			m.variants[i] = commit.ApplyIssue(v, m.cfg.Commit.Issue, m.issueKey)
+		}
+		m.variantsRequest = msg.request
+		m.variantsStreaming = false
+		m.streamCh = nil
 		m.state = stateVariantPicking
 		return m, nil
 
+	case streamChunkMsg:
+		// Keep draining superseded streams so their producers never block,
+		// but only apply chunks from the active one.
+		if msg.ch != m.streamCh {
+			return m, waitForStream(msg.ch)
+		}
+		if msg.progress != "" {
+			m.loader.SetMessage(msg.progress)
+		} else if msg.slot < 0 {
+			m.streamText += msg.chunk
+		} else if msg.slot < len(m.variants) {
+			m.variants[msg.slot] += msg.chunk
+		}
+		return m, waitForStream(msg.ch)
+
 	case commitProgressMsg:
 		m.commitProgress = msg.line
 		return m, waitForCommitLine(m.commitOutputCh)
 
 	case splitPlanReadyMsg:
+		if m.state != stateSplitGenerating && !m.splitRegenerating {
+			return m, nil
+		}
+		m.cancel = nil
+		m.retry = nil
+		m.streamCh = nil
 		m.splitPlan = msg.plan
+		for i := range m.splitPlan {
+			m.splitPlan[i].Message = commit.ApplyIssue(m.splitPlan[i].Message, m.cfg.Commit.Issue, m.issueKey)
+		}
+		m.splitDiff = map[string]git.FileDiff{}
+		for _, fd := range git.ParseDiff(m.diff) {
+			m.splitDiff[fd.Path] = fd
+		}
+		m.splitCached = msg.cached
+		m.splitRequest = msg.request
 		m.splitCursor = 0
 		m.splitExpanded = map[int]bool{}
 		m.splitRegenerating = false
@@ -165,6 +260,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case splitCommitDoneMsg:
+		m.cancel = nil
 		m.splitHashes = msg.hashes
 		m.splitPushed = m.cliPush
 		m.state = stateSplitSuccess
@@ -172,10 +268,25 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case splitCommitFailureMsg:
 		failure := msg
+		m.cancel = nil
 		m.splitFailure = &failure
 		m.splitHashes = msg.committedHashes
+		m.splitRestored = false
+		m.statusMessage = ""
+		m.statusIsError = false
 		m.state = stateSplitFailure
 		return m, nil
+
+	case indexRestoredMsg:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Failed to restore the index: %v", msg.err)
+			m.statusIsError = true
+			return m, nil
+		}
+		m.splitRestored = true
+		m.statusMessage = "Original index restored"
+		m.statusIsError = false
+		return m, nil
 	}
 
 	// Update sub-components
@@ -184,6 +295,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case stateWelcome, stateGenerating, stateCommitting, stateSplitGenerating, stateSplitCommitting:
 		m.loader, cmd = m.loader.Update(msg)
 		return m, cmd
+	case stateVariantPicking:
+		if m.variantsStreaming {
+			m.loader, cmd = m.loader.Update(msg)
+			return m, cmd
+		}
 	case stateSplitPlan:
 		if m.splitRegenerating {
 			m.loader, cmd = m.loader.Update(msg)
@@ -195,7 +311,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case stateEditing:
 		m.textarea, cmd = m.textarea.Update(msg)
 		return m, cmd
-	case stateDiffView:
+	case stateDiffView, stateRequestView:
 		m.viewport, cmd = m.viewport.Update(msg)
 		return m, cmd
 	}
@@ -219,8 +335,12 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleVariantPickingKey(msg)
 	case stateDiffView:
 		return m.handleDiffViewKey(msg)
+	case stateRequestView:
+		return m.handleRequestViewKey(msg)
 	case stateTypePicker:
 		return m.handleTypePickerKey(msg)
+	case stateCoAuthorPicker:
+		return m.handleCoAuthorPickerKey(msg)
 	case stateFilePicker:
 		return m.handleFilePickerKey(msg)
 	case stateSplitPlan:
@@ -232,14 +352,51 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		}
 	case stateSplitFailure:
-		if msg.String() == "q" || msg.String() == "ctrl+c" || msg.String() == "enter" {
+		switch msg.String() {
+		case "q", "ctrl+c", "enter":
 			return m, tea.Quit
+		case "r":
+			if m.splitFailure != nil && m.splitFailure.snapshot != "" && !m.splitRestored {
+				return m, doRestoreIndex(m.splitFailure.snapshot)
+			}
 		}
-	case stateError, stateSuccess:
+	case stateError:
+		switch msg.String() {
+		case "r":
+			if m.retry != nil {
+				m.err = nil
+				return m.retry(m)
+			}
+		case "o":
+			if m.canGoOffline() {
+				m.err = nil
+				m.offline = true
+				return m.generate(nil)
+			}
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		}
+	case stateSuccess:
 		if msg.String() == "q" || msg.String() == "ctrl+c" {
 			return m, tea.Quit
 		}
-	case stateWelcome, stateGenerating, stateCommitting, stateSplitGenerating, stateSplitCommitting:
+	case stateGenerating, stateSplitGenerating:
+		return m.handleGeneratingKey(msg)
+	case stateSplitCommitting:
+		// The first esc or ctrl+c stops the split before its next group so
+		// the index can be restored; a second ctrl+c quits outright.
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			if !m.splitAborting {
+				m.cancelRequest()
+				m.splitAborting = true
+				return m, nil
+			}
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+		}
+	case stateWelcome, stateCommitting:
 		if msg.String() == "ctrl+c" {
 			return m, tea.Quit
 		}
@@ -247,7 +404,61 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleGeneratingKey lets the user abandon a generation in progress: esc
+// cancels the request and returns to where it was started from, q/ctrl+c
+// cancel and quit.
+func (m model) handleGeneratingKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		m.cancelRequest()
+		return m, tea.Quit
+	case "esc":
+		m.cancelRequest()
+		if m.pendingAmend && m.amend == nil {
+			// HEAD never loaded, so there is nothing to amend yet.
+			m.pendingAmend = false
+			m.amendConfirmed = false
+		}
+		if m.state == stateSplitGenerating {
+			m.state = stateReady
+			return m, nil
+		}
+		if m.commitMessage == "" {
+			// Initial generation: there is nothing to go back to.
+			return m, tea.Quit
+		}
+		// Regenerate and feedback push the current message onto the
+		// history before starting; undo that so it is not listed twice.
+		if n := len(m.previousMessages); n > 0 && m.previousMessages[n-1] == m.commitMessage {
+			m.previousMessages = m.previousMessages[:n-1]
+		}
+		m.state = stateReady
+		m.statusMessage = "Generation cancelled"
+		m.statusIsError = false
+		return m, nil
+	}
+	return m, nil
+}
+
 func (m model) handleReadyKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.offline && offlineUnavailableKeys[msg.String()] {
+		m.statusMessage = "Not available offline — rerun without --offline"
+		m.statusIsError = true
+		return m, nil
+	}
+
+	if m.pendingAmend && m.amend == nil && (msg.String() == "enter" || msg.String() == "n" || msg.String() == "p") {
+		m.statusMessage = "HEAD has not been loaded for amending; press A to load it again"
+		m.statusIsError = true
+		return m, nil
+	}
+	if m.needsAmendConfirm() && (msg.String() == "enter" || msg.String() == "n" || msg.String() == "p") {
+		m.amendConfirmed = true
+		m.statusMessage = fmt.Sprintf("HEAD is already on %s; amending rewrites published history. Press %s again to amend anyway.", m.amend.upstream, msg.String())
+		m.statusIsError = true
+		return m, nil
+	}
+
 	switch {
 	case msg.String() == "enter":
 		m.state = stateCommitting
@@ -255,31 +466,29 @@ func (m model) handleReadyKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.commitProgress = ""
 		ch := make(chan string, 20)
 		m.commitOutputCh = ch
-		return m, tea.Batch(doCommit(m.commitMessage, false, false, m.pendingAmend, m.cfg, ch), waitForCommitLine(ch), m.loader.Tick)
+		return m, tea.Batch(doCommit(m.commitMessage, m.trailers, false, false, m.pendingAmend, m.cfg, ch), waitForCommitLine(ch), m.loader.Tick)
 	case msg.String() == "n":
 		m.state = stateCommitting
 		m.loader = loader.New(loader.CommittingMessages)
 		m.commitProgress = ""
 		ch := make(chan string, 20)
 		m.commitOutputCh = ch
-		return m, tea.Batch(doCommit(m.commitMessage, false, true, m.pendingAmend, m.cfg, ch), waitForCommitLine(ch), m.loader.Tick)
+		return m, tea.Batch(doCommit(m.commitMessage, m.trailers, false, true, m.pendingAmend, m.cfg, ch), waitForCommitLine(ch), m.loader.Tick)
 	case msg.String() == "p":
 		m.state = stateCommitting
 		m.loader = loader.New(loader.CommittingMessages)
 		m.commitProgress = ""
 		ch := make(chan string, 20)
 		m.commitOutputCh = ch
-		return m, tea.Batch(doCommit(m.commitMessage, true, false, m.pendingAmend, m.cfg, ch), waitForCommitLine(ch), m.loader.Tick)
+		return m, tea.Batch(doCommit(m.commitMessage, m.trailers, true, false, m.pendingAmend, m.cfg, ch), waitForCommitLine(ch), m.loader.Tick)
 	case msg.String() == "r":
-		m.state = stateGenerating
-		m.loader = loader.New(loader.GeneratingMessages)
 		prev := m.previousMessages
 		m.previousMessages = append(m.previousMessages, m.commitMessage)
-		return m, tea.Batch(m.loader.Tick, doRegenerate(m.diff, m.cfg, prev, m.commitMessage))
+		return m.generate(func(ctx context.Context, ch chan streamChunkMsg) tea.Cmd {
+			return doRegenerate(ctx, m.diff, m.cfg, prev, m.commitMessage, ch)
+		})
 	case msg.String() == "v":
-		m.state = stateGenerating
-		m.loader = loader.New(loader.VariantMessages)
-		return m, tea.Batch(m.loader.Tick, doGenerateVariants(m.diff, m.cfg, m.previousMessages, m.commitMessage))
+		return m.generateVariants()
 	case msg.String() == "f":
 		m.state = stateFeedback
 		m.textinput = textinput.New()
@@ -291,24 +500,37 @@ func (m model) handleReadyKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case msg.String() == "e":
 		m.state = stateEditing
 		m.textarea = textarea.New()
-		m.textarea.SetValue(m.commitMessage)
+		m.textarea.SetValue(m.editableMessage())
 		m.textarea.SetHeight(8)
 		m.textarea.SetWidth(60)
 		m.textarea.Focus()
 		return m, m.textarea.Cursor.BlinkCmd()
 	case msg.String() == "E":
 		return m.openExternalEditor()
+	case msg.String() == "c":
+		if len(m.cfg.Commit.Trailers.CoAuthors) == 0 {
+			m.statusMessage = "No co-authors configured — add them under commit.trailers.co_authors"
+			m.statusIsError = false
+			return m, nil
+		}
+		m.coAuthorCursor = 0
+		m.state = stateCoAuthorPicker
+		return m, nil
 	case msg.String() == "A":
 		m.pendingAmend = true
-		m.state = stateGenerating
-		m.loader = loader.New(loader.GeneratingMessages)
-		return m, tea.Batch(m.loader.Tick, loadDiffAndGenerate(m.cfg))
+		m.amendConfirmed = false
+		return m.generate(func(ctx context.Context, ch chan streamChunkMsg) tea.Cmd {
+			return loadAmendAndGenerate(ctx, m.cfg, ch)
+		})
 	case msg.String() == "d":
 		vp := viewport.New(m.width-6, m.height-8)
 		vp.SetContent(m.diff)
 		m.viewport = vp
 		m.state = stateDiffView
 		return m, nil
+	case msg.String() == "P":
+		m.requestReturn = stateReady
+		return m, doDescribeRequest(m.diff, m.request, m.cfg)
 	case msg.String() == "[":
 		if len(m.previousMessages) == 0 {
 			return m, nil
@@ -353,11 +575,11 @@ func (m model) handleReadyKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		m.statusMessage = fmt.Sprintf("Length: %s", m.cfg.Commit.Length)
 		m.statusIsError = false
-		m.state = stateGenerating
-		m.loader = loader.New(loader.GeneratingMessages)
 		prev := m.previousMessages
 		m.previousMessages = append(m.previousMessages, m.commitMessage)
-		return m, tea.Batch(m.loader.Tick, doRegenerate(m.diff, m.cfg, prev, m.commitMessage))
+		return m.generate(func(ctx context.Context, ch chan streamChunkMsg) tea.Cmd {
+			return doRegenerate(ctx, m.diff, m.cfg, prev, m.commitMessage, ch)
+		})
 	case msg.String() == "M":
 		m.cfg.Commit.Emoji = !m.cfg.Commit.Emoji
 		emojiStatus := "off"
@@ -366,17 +588,22 @@ func (m model) handleReadyKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		m.statusMessage = fmt.Sprintf("Emoji: %s", emojiStatus)
 		m.statusIsError = false
-		m.state = stateGenerating
-		m.loader = loader.New(loader.GeneratingMessages)
 		prev := m.previousMessages
 		m.previousMessages = append(m.previousMessages, m.commitMessage)
-		return m, tea.Batch(m.loader.Tick, doRegenerate(m.diff, m.cfg, prev, m.commitMessage))
+		return m.generate(func(ctx context.Context, ch chan streamChunkMsg) tea.Cmd {
+			return doRegenerate(ctx, m.diff, m.cfg, prev, m.commitMessage, ch)
+		})
 	case msg.String() == "x":
 		m.state = stateFilePicker
 		return m, loadAllFiles()
 	case msg.String() == "s":
-		return m, doSaveDraft(m.commitMessage)
+		return m, doSaveDraft(m.commitMessage, m.trailers)
 	case msg.String() == "S":
+		if m.pendingAmend {
+			m.statusMessage = "Split is not available while amending"
+			m.statusIsError = true
+			return m, nil
+		}
 		if m.cliPrint {
 			m.statusMessage = "--print is incompatible with split; rerun without --print"
 			m.statusIsError = true
@@ -392,11 +619,9 @@ func (m model) handleReadyKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.statusIsError = false
 			return m, nil
 		}
-		m.state = stateSplitGenerating
-		m.loader = loader.New(loader.GeneratingMessages)
-		return m, tea.Batch(m.loader.Tick, loadSplitPlan(m.diff, m.cfg, m.stagedFiles, nil, ""))
+		return m.planSplit(nil, "")
 	case msg.String() == "y":
-		return m, doCopy(m.commitMessage)
+		return m, doCopy(m.commitMessage, m.trailers)
 	case msg.String() == "?":
 		m.state = stateHelp
 		return m, nil
@@ -414,10 +639,10 @@ func (m model) handleFeedbackKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.state = stateReady
 			return m, nil
 		}
-		m.state = stateGenerating
-		m.loader = loader.New(loader.GeneratingMessages)
 		m.previousMessages = append(m.previousMessages, m.commitMessage)
-		return m, tea.Batch(m.loader.Tick, doFeedback(m.diff, m.cfg, m.commitMessage, feedback))
+		return m.generate(func(ctx context.Context, ch chan streamChunkMsg) tea.Cmd {
+			return doFeedback(ctx, m.diff, m.cfg, m.commitMessage, feedback, ch)
+		})
 	case "esc":
 		m.state = stateReady
 		return m, nil
@@ -431,10 +656,7 @@ func (m model) handleFeedbackKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m model) handleEditingKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
-		newMsg := strings.TrimSpace(m.textarea.Value())
-		if newMsg != "" {
-			m.commitMessage = newMsg
-		}
+		m = m.acceptEdit(m.textarea.Value())
 		m.state = stateReady
 		return m, nil
 	}
@@ -487,6 +709,21 @@ func (m model) handleNoStagedKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) handleVariantPickingKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.variantsStreaming {
+		switch msg.String() {
+		case "q", "ctrl+c":
+			m.cancelRequest()
+			return m, tea.Quit
+		case "esc":
+			m.cancelRequest()
+			m.variantsStreaming = false
+			m.variants = nil
+			m.state = stateReady
+			return m, nil
+		}
+		return m, nil
+	}
+
 	switch msg.String() {
 	case "up", "k":
 		if m.variantCursor > 0 {
@@ -516,6 +753,8 @@ func (m model) handleVariantPickingKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m model) selectVariant() (model, tea.Cmd) {
 	m.previousMessages = append(m.previousMessages, m.commitMessage)
 	m.commitMessage = m.variants[m.variantCursor]
+	m.request = m.variantsRequest
+	m.cached = false
 	m.variants = nil
 	m.state = stateReady
 	m.statusMessage = "Variant selected"
@@ -536,6 +775,19 @@ func (m model) handleDiffViewKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func (m model) handleRequestViewKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "P", "q":
+		m.state = m.requestReturn
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
 func (m model) handleTypePickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "up", "k":
@@ -563,12 +815,59 @@ func (m model) handleTypePickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m model) handleCoAuthorPickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	roster := m.cfg.Commit.Trailers.CoAuthors
+	switch msg.String() {
+	case "up", "k":
+		if m.coAuthorCursor > 0 {
+			m.coAuthorCursor--
+		}
+	case "down", "j":
+		if m.coAuthorCursor < len(roster)-1 {
+			m.coAuthorCursor++
+		}
+	case " ":
+		trailer := commit.CoAuthorTrailer(roster[m.coAuthorCursor])
+		if i := slices.Index(m.trailers, trailer); i >= 0 {
+			m.trailers = slices.Delete(slices.Clone(m.trailers), i, i+1)
+		} else {
+			m.trailers = append(slices.Clone(m.trailers), trailer)
+		}
+	case "enter", "esc", "q":
+		m.state = stateReady
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// editableMessage is the message with its trailers, as shown for editing.
+func (m model) editableMessage() string {
+	if len(m.trailers) == 0 {
+		return m.commitMessage
+	}
+	return m.commitMessage + "\n\n" + strings.Join(m.trailers, "\n")
+}
+
+// acceptEdit takes an edited editableMessage apart again, so trailers the
+// user added, changed or removed stay out of later prompts.
+func (m model) acceptEdit(edited string) model {
+	edited = strings.TrimSpace(edited)
+	if edited == "" || edited == m.editableMessage() {
+		return m
+	}
+	m.commitMessage, m.trailers = commit.SplitTrailers(edited)
+	m.cached = false
+	return m
+}
+
 func (m model) selectType() (model, tea.Cmd) {
 	selected := conventionalTypes[m.typeCursor]
 	m.previousMessages = append(m.previousMessages, m.commitMessage)
-	m.state = stateGenerating
-	m.loader = loader.New(loader.GeneratingMessages)
-	return m, tea.Batch(m.loader.Tick, doFeedback(m.diff, m.cfg, m.commitMessage, "Force type prefix: "+selected))
+	return m.generate(func(ctx context.Context, ch chan streamChunkMsg) tea.Cmd {
+		return doFeedback(ctx, m.diff, m.cfg, m.commitMessage, "Force type prefix: "+selected, ch)
+	})
 }
 
 func (m model) handleFilePickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -634,14 +933,82 @@ func (m model) checkWelcomeDone() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Amending can just reword HEAD, so it needs nothing staged.
+	if m.pendingAmend {
+		return m.generate(func(ctx context.Context, ch chan streamChunkMsg) tea.Cmd {
+			return loadAmendAndGenerate(ctx, m.cfg, ch)
+		})
+	}
+
 	if len(m.stagedFiles) == 0 {
 		m.state = stateNoStaged
 		return m, loadUnstagedFiles()
 	}
 
+	return m.generate(func(ctx context.Context, ch chan streamChunkMsg) tea.Cmd {
+		return loadDiffAndGenerate(ctx, m.cfg, ch)
+	})
+}
+
+// generate switches to stateGenerating and starts gen with a fresh request
+// context and stream. gen is kept so the retry key in stateError can run
+// the same request again.
+func (m model) generate(gen func(ctx context.Context, ch chan streamChunkMsg) tea.Cmd) (model, tea.Cmd) {
+	if m.offline {
+		cfg := m.cfg
+		gen = func(_ context.Context, ch chan streamChunkMsg) tea.Cmd { return doGenerateOffline(cfg, ch) }
+	}
 	m.state = stateGenerating
 	m.loader = loader.New(loader.GeneratingMessages)
-	return m, tea.Batch(m.loader.Tick, loadDiffAndGenerate(m.cfg))
+	ctx := m.startRequest()
+	ch := m.openStream()
+	m.retry = func(m model) (model, tea.Cmd) { return m.generate(gen) }
+	return m, tea.Batch(m.loader.Tick, gen(ctx, ch), waitForStream(ch))
+}
+
+// needsAmendConfirm reports whether committing would amend a HEAD that is
+// already on its upstream and the user has not yet confirmed it.
+func (m model) needsAmendConfirm() bool {
+	return m.pendingAmend && m.amend != nil && m.amend.upstream != "" && !m.amendConfirmed
+}
+
+// offlineUnavailableKeys are ready-view actions that only make sense with a
+// model behind them.
+var offlineUnavailableKeys = map[string]bool{"r": true, "v": true, "f": true, "t": true, "S": true}
+
+// canGoOffline reports whether the error view should offer an offline
+// message: only when the backend could not be reached at all.
+func (m model) canGoOffline() bool {
+	return !m.offline && backend.IsNetwork(m.err)
+}
+
+func (m model) generateVariants() (model, tea.Cmd) {
+	m.state = stateVariantPicking
+	m.loader = loader.New(loader.VariantMessages)
+	m.variants = make([]string, variantCount)
+	m.variantCursor = 0
+	m.variantsStreaming = true
+	ctx := m.startRequest()
+	ch := m.openStream()
+	m.retry = func(m model) (model, tea.Cmd) { return m.generateVariants() }
+	return m, tea.Batch(m.loader.Tick, doGenerateVariants(ctx, m.diff, m.cfg, m.previousMessages, m.commitMessage, ch), waitForStream(ch))
+}
+
+// planSplit requests a split plan. With no plan on screen it shows the
+// planning spinner; otherwise the current plan stays visible under a
+// regenerating overlay.
+func (m model) planSplit(prev [][]commit.SplitGroup, feedback string) (model, tea.Cmd) {
+	m.loader = loader.New(loader.GeneratingMessages)
+	if m.splitPlan == nil {
+		m.state = stateSplitGenerating
+	} else {
+		m.state = stateSplitPlan
+		m.splitRegenerating = true
+	}
+	ctx := m.startRequest()
+	ch := m.openStream()
+	m.retry = func(m model) (model, tea.Cmd) { return m.planSplit(prev, feedback) }
+	return m, tea.Batch(m.loader.Tick, loadSplitPlan(ctx, m.diff, m.cfg, m.stagedFiles, prev, feedback, ch), waitForStream(ch))
 }
 
 func (m model) handleSplitFeedbackKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -655,10 +1022,7 @@ func (m model) handleSplitFeedbackKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		prev := append([][]commit.SplitGroup(nil), m.splitPrevPlans...)
 		prev = append(prev, clonePlan(m.splitPlan))
 		m.splitPrevPlans = prev
-		m.splitRegenerating = true
-		m.state = stateSplitPlan
-		m.loader = loader.New(loader.GeneratingMessages)
-		return m, tea.Batch(m.loader.Tick, loadSplitPlan(m.diff, m.cfg, m.stagedFiles, prev, feedback))
+		return m.planSplit(prev, feedback)
 	case "esc":
 		m.state = stateSplitPlan
 		return m, nil
@@ -675,8 +1039,20 @@ func (m model) handleSplitPlanKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleSplitMoveKey(msg)
 	}
 
+	if m.splitRegenerating && msg.String() == "esc" {
+		// Cancel the regeneration but keep the current plan on screen.
+		m.cancelRequest()
+		m.splitRegenerating = false
+		if n := len(m.splitPrevPlans); n > 0 {
+			m.splitPrevPlans = m.splitPrevPlans[:n-1]
+		}
+		return m, nil
+	}
+
 	switch msg.String() {
 	case "q", "esc":
+		m.cancelRequest()
+		m.splitRegenerating = false
 		m.state = stateReady
 		m.splitPlan = nil
 		m.splitExpanded = nil
@@ -685,6 +1061,7 @@ func (m model) handleSplitPlanKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.statusIsError = false
 		return m, nil
 	case "ctrl+c":
+		m.cancelRequest()
 		return m, tea.Quit
 	case "up", "k":
 		if m.splitCursor > 0 {
@@ -714,9 +1091,7 @@ func (m model) handleSplitPlanKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		prev := append([][]commit.SplitGroup(nil), m.splitPrevPlans...)
 		prev = append(prev, clonePlan(m.splitPlan))
 		m.splitPrevPlans = prev
-		m.splitRegenerating = true
-		m.loader = loader.New(loader.GeneratingMessages)
-		return m, tea.Batch(m.loader.Tick, loadSplitPlan(m.diff, m.cfg, m.stagedFiles, prev, ""))
+		return m.planSplit(prev, "")
 	case "f":
 		m.state = stateSplitFeedback
 		m.textinput = textinput.New()
@@ -729,7 +1104,7 @@ func (m model) handleSplitPlanKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.splitCursor < 0 || m.splitCursor >= len(m.splitPlan) {
 			return m, nil
 		}
-		if len(m.splitPlan[m.splitCursor].Files) == 0 {
+		if len(splitItems(m.splitPlan[m.splitCursor])) == 0 {
 			return m, nil
 		}
 		m.splitMoveMode = true
@@ -743,10 +1118,15 @@ func (m model) handleSplitPlanKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.statusMessage = ""
 		m.statusIsError = false
 		return m, nil
+	case "P":
+		m.requestReturn = stateSplitPlan
+		return m, doDescribeRequest(m.diff, m.splitRequest, m.cfg)
 	case "c":
 		m.state = stateSplitCommitting
+		m.splitAborting = false
 		m.loader = loader.New(loader.CommittingMessages)
-		return m, tea.Batch(m.loader.Tick, doExecuteSplit(m.splitPlan, m.cliNoVerify, m.cliPush, m.cfg))
+		ctx := m.startRequest()
+		return m, tea.Batch(m.loader.Tick, doExecuteSplit(ctx, m.splitPlan, m.diff, m.trailers, m.cliNoVerify, m.cliPush, m.cfg))
 	}
 	return m, nil
 }
@@ -774,10 +1154,12 @@ func (m model) handleSplitMoveKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 	case "down", "j":
-		if m.splitMoveFileIdx < len(srcGroup.Files)-1 {
+		if m.splitMoveFileIdx < len(splitItems(srcGroup))-1 {
 			m.splitMoveFileIdx++
 		}
 		return m, nil
+	case "h":
+		return m.splitFileIntoHunks()
 	case "enter":
 		// Enter destination picker (arrow selection)
 		m.splitMovePickDest = true
@@ -868,16 +1250,15 @@ func (m model) applySplitMove(dest int) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	srcGroup := &m.splitPlan[src]
-	if m.splitMoveFileIdx < 0 || m.splitMoveFileIdx >= len(srcGroup.Files) {
+	if m.splitMoveFileIdx < 0 || m.splitMoveFileIdx >= len(splitItems(*srcGroup)) {
 		m = m.exitSplitMoveMode()
 		return m, nil
 	}
-	file := srcGroup.Files[m.splitMoveFileIdx]
-	srcGroup.Files = append(srcGroup.Files[:m.splitMoveFileIdx], srcGroup.Files[m.splitMoveFileIdx+1:]...)
-	m.splitPlan[dest].Files = append(m.splitPlan[dest].Files, file)
+	item := removeSplitItem(srcGroup, m.splitMoveFileIdx)
+	addSplitItem(&m.splitPlan[dest], item)
 
 	// If source group is now empty, remove it and adjust cursor.
-	if len(srcGroup.Files) == 0 {
+	if len(splitItems(*srcGroup)) == 0 {
 		m.splitPlan = append(m.splitPlan[:src], m.splitPlan[src+1:]...)
 		// Renumber and fix orders.
 		for i := range m.splitPlan {
@@ -903,12 +1284,82 @@ func (m model) applySplitMove(dest int) (tea.Model, tea.Cmd) {
 		}
 		m.splitExpanded = newExp
 	}
-	m.statusMessage = fmt.Sprintf("moved %s", file)
+	m.statusMessage = fmt.Sprintf("moved %s", item)
 	m.statusIsError = false
 	m = m.exitSplitMoveMode()
 	return m, nil
 }
 
+// splitFileIntoHunks replaces the whole file under the move cursor with its
+// hunks, so they can be reassigned one by one.
+func (m model) splitFileIntoHunks() (tea.Model, tea.Cmd) {
+	g := &m.splitPlan[m.splitCursor]
+	if m.splitMoveFileIdx >= len(g.Files) {
+		return m, nil
+	}
+	file := g.Files[m.splitMoveFileIdx]
+	n := len(m.splitDiff[file].Hunks)
+	if n < 2 {
+		m.statusMessage = fmt.Sprintf("%s has no hunks to split", file)
+		m.statusIsError = true
+		return m, nil
+	}
+	removeSplitItem(g, m.splitMoveFileIdx)
+	for i := 1; i <= n; i++ {
+		g.Hunks = append(g.Hunks, commit.HunkRef{File: file, Hunk: i})
+	}
+	m.splitMoveFileIdx = len(splitItems(*g)) - n
+	m.statusMessage = fmt.Sprintf("split %s into %d hunks", file, n)
+	m.statusIsError = false
+	return m, nil
+}
+
+// splitItem is one movable entry of a split group: a whole file, or one of
+// its hunks when hunk > 0.
+type splitItem struct {
+	file string
+	hunk int
+}
+
+func (it splitItem) String() string {
+	if it.hunk == 0 {
+		return it.file
+	}
+	return fmt.Sprintf("hunk %d of %s", it.hunk, it.file)
+}
+
+// splitItems lists a group's whole files, then its hunks.
+func splitItems(g commit.SplitGroup) []splitItem {
+	items := make([]splitItem, 0, len(g.Files)+len(g.Hunks))
+	for _, f := range g.Files {
+		items = append(items, splitItem{file: f})
+	}
+	for _, h := range g.Hunks {
+		items = append(items, splitItem{file: h.File, hunk: h.Hunk})
+	}
+	return items
+}
+
+func removeSplitItem(g *commit.SplitGroup, i int) splitItem {
+	if i < len(g.Files) {
+		file := g.Files[i]
+		g.Files = append(g.Files[:i], g.Files[i+1:]...)
+		return splitItem{file: file}
+	}
+	i -= len(g.Files)
+	h := g.Hunks[i]
+	g.Hunks = append(g.Hunks[:i], g.Hunks[i+1:]...)
+	return splitItem{file: h.File, hunk: h.Hunk}
+}
+
+func addSplitItem(g *commit.SplitGroup, it splitItem) {
+	if it.hunk == 0 {
+		g.Files = append(g.Files, it.file)
+		return
+	}
+	g.Hunks = append(g.Hunks, commit.HunkRef{File: it.file, Hunk: it.hunk})
+}
+
 func (m model) exitSplitMoveMode() model {
 	m.splitMoveMode = false
 	m.splitMovePickDest = false
@@ -966,7 +1417,7 @@ func (m model) openExternalEditor() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	if _, err := tmpFile.WriteString(m.commitMessage); err != nil {
+	if _, err := tmpFile.WriteString(m.editableMessage()); err != nil {
 		os.Remove(tmpFile.Name())
 		m.statusMessage = "Failed to write temp file"
 		m.statusIsError = true
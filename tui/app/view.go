@@ -2,9 +2,12 @@ package app
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/dinoDanic/diny/backend"
+	"github.com/dinoDanic/diny/commit"
 	"github.com/dinoDanic/diny/tui/shared"
 )
 
@@ -13,6 +16,11 @@ func (m model) View() string {
 
 	b.WriteString("\n")
 	b.WriteString(shared.RenderHeader(m.version, m.repoName, m.branchName, m.width))
+	if m.repoLoaded && m.cfg.Commit.Issue.Pattern != "" && m.issueKey == "" {
+		warning := fmt.Sprintf("No issue key in branch %q — messages go out without one", m.branchName)
+		b.WriteString(indentStyle().Render(warningStyle().Render(warning)))
+		b.WriteString("\n")
+	}
 	b.WriteString("\n")
 
 	switch m.state {
@@ -40,6 +48,8 @@ func (m model) View() string {
 		b.WriteString(m.renderDiffView())
 	case stateTypePicker:
 		b.WriteString(m.renderTypePicker())
+	case stateCoAuthorPicker:
+		b.WriteString(m.renderCoAuthorPicker())
 	case stateFilePicker:
 		b.WriteString(m.renderFilePicker())
 	case stateError:
@@ -56,6 +66,8 @@ func (m model) View() string {
 		b.WriteString(m.renderSplitFailure())
 	case stateSplitFeedback:
 		b.WriteString(m.renderSplitFeedback())
+	case stateRequestView:
+		b.WriteString(m.renderRequestView())
 	}
 
 	return b.String()
@@ -77,6 +89,13 @@ func (m model) renderGenerating() string {
 		b.WriteString("\n")
 	}
 
+	if m.streamText != "" {
+		b.WriteString(indent.Render(sectionTitleStyle().Render("Commit Message")))
+		b.WriteString("\n")
+		b.WriteString(indent.Render(commitMessageStyle().Render(m.streamText)))
+		b.WriteString("\n\n")
+	}
+
 	b.WriteString(indent.Render(m.loader.View()))
 	b.WriteString("\n")
 
@@ -89,9 +108,41 @@ func (m model) renderGenerating() string {
 		b.WriteString(m.renderStatus())
 	}
 
+	b.WriteString("\n")
+	b.WriteString(m.renderCancelHint())
+
 	return b.String()
 }
 
+// renderRedactionBanner lists the files in which secrets were replaced
+// with placeholders before the diff was sent.
+func (m model) renderRedactionBanner() string {
+	total := m.redactions.Total()
+	noun := "secrets"
+	if total == 1 {
+		noun = "secret"
+	}
+	parts := make([]string, 0, len(m.redactions))
+	for _, fc := range m.redactions {
+		file := fc.File
+		if file == "" {
+			file = "prompt"
+		}
+		parts = append(parts, fmt.Sprintf("%s (%d)", file, fc.Count))
+	}
+	text := fmt.Sprintf("⚠ redacted %d %s before sending: %s", total, noun, strings.Join(parts, ", "))
+	return indentStyle().Render(warningStyle().Render(text)) + "\n"
+}
+
+// renderCancelHint is the footer shown while a generation is in flight.
+func (m model) renderCancelHint() string {
+	indent := indentStyle()
+	return indent.Render(
+		footerKeyStyle().Render("esc")+" "+footerDescStyle().Render("cancel")+"  "+
+			footerKeyStyle().Render("q")+" "+footerDescStyle().Render("quit"),
+	) + "\n"
+}
+
 func (m model) renderReady() string {
 	var b strings.Builder
 	indent := indentStyle()
@@ -99,7 +150,11 @@ func (m model) renderReady() string {
 	b.WriteString("\n")
 	b.WriteString(m.renderStagedFiles())
 	b.WriteString("\n")
-	b.WriteString(m.renderCommitMessage())
+	if m.pendingAmend && m.amend != nil {
+		b.WriteString(m.renderAmendMessages())
+	} else {
+		b.WriteString(m.renderCommitMessage())
+	}
 	b.WriteString("\n")
 
 	if m.messageHistoryIdx != -1 {
@@ -109,7 +164,25 @@ func (m model) renderReady() string {
 	}
 
 	if m.pendingAmend {
-		b.WriteString(indent.Render(metaStyle().Render("amend mode")))
+		b.WriteString(indent.Render(metaStyle().Render("amend mode — committing replaces HEAD")))
+		b.WriteString("\n")
+		if m.amend != nil && m.amend.upstream != "" {
+			b.WriteString(indent.Render(warningStyle().Render("⚠ HEAD is already on " + m.amend.upstream + "; amending rewrites published history")))
+			b.WriteString("\n")
+		}
+	}
+
+	if m.redactions.Total() > 0 {
+		b.WriteString(m.renderRedactionBanner())
+	}
+
+	if m.cached && m.messageHistoryIdx == -1 {
+		b.WriteString(indent.Render(metaStyle().Render("cached — press r to regenerate")))
+		b.WriteString("\n")
+	}
+
+	if m.offline {
+		b.WriteString(indent.Render(metaStyle().Render("offline — built from staged files, no backend used")))
 		b.WriteString("\n")
 	}
 
@@ -179,7 +252,7 @@ func (m model) renderHelp() string {
 		{"enter", "Commit"},
 		{"n", "Commit (skip hooks / no-verify)"},
 		{"p", "Commit and push"},
-		{"A", "Regenerate from HEAD diff and amend on commit"},
+		{"A", "Amend HEAD: refine its message from the HEAD~1-to-index diff"},
 		{"r", "Regenerate commit message"},
 		{"v", "Pick from 3 variants"},
 		{"f", "Refine with feedback"},
@@ -188,7 +261,9 @@ func (m model) renderHelp() string {
 		{"M", "Toggle emoji on/off (session only)"},
 		{"e", "Edit inline"},
 		{"E", "Edit in $EDITOR"},
+		{"c", "Pick co-authors (commit.trailers.co_authors)"},
 		{"d", "View staged diff"},
+		{"P", "Show the exact request behind this message (dry run)"},
 		{"[", "Browse previous generated messages"},
 		{"]", "Browse forward through message history"},
 		{"x", "Manage staged/unstaged files"},
@@ -196,6 +271,7 @@ func (m model) renderHelp() string {
 		{"s", "Save as draft"},
 		{"y", "Copy to clipboard"},
 		{"?", "Toggle help"},
+		{"esc", "Cancel a generation in progress and go back"},
 		{"q", "Quit"},
 	}
 
@@ -321,6 +397,11 @@ func (m model) renderVariantPicking() string {
 	b.WriteString(indent.Render(sectionTitleStyle().Render("Pick a Variant")))
 	b.WriteString("\n\n")
 
+	if m.variantsStreaming {
+		b.WriteString(indent.Render(m.loader.View()))
+		b.WriteString("\n\n")
+	}
+
 	for i, v := range m.variants {
 		cursor := "  "
 		if i == m.variantCursor {
@@ -334,11 +415,19 @@ func (m model) renderVariantPicking() string {
 			style = metaStyle()
 		}
 
+		if v == "" {
+			v = "…"
+		}
 		line := cursor + style.Render(fmt.Sprintf("%d. %s", i+1, v))
 		b.WriteString(indent.Render(line))
 		b.WriteString("\n\n")
 	}
 
+	if m.variantsStreaming {
+		b.WriteString(m.renderCancelHint())
+		return b.String()
+	}
+
 	keys := []struct{ key, desc string }{
 		{"↑/k", "up"}, {"↓/j", "down"}, {"1/2/3", "pick"}, {"enter", "select"}, {"esc", "cancel"},
 	}
@@ -367,6 +456,25 @@ func (m model) renderDiffView() string {
 	return b.String()
 }
 
+func (m model) renderRequestView() string {
+	indent := indentStyle()
+	var b strings.Builder
+
+	b.WriteString("\n")
+	title := "Request behind this result — dry run, nothing sent"
+	if m.requestCached {
+		title = "Served from cache, no request sent — this is the request that would be sent"
+	}
+	b.WriteString(indent.Render(sectionTitleStyle().Render(title)))
+	b.WriteString("\n\n")
+	b.WriteString(indent.Render(m.viewport.View()))
+	b.WriteString("\n\n")
+	b.WriteString(indent.Render(metaStyle().Render("↑/k up  ↓/j down  pgup/pgdn scroll  esc close")))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
 func (m model) renderTypePicker() string {
 	indent := indentStyle()
 	var b strings.Builder
@@ -407,6 +515,43 @@ func (m model) renderTypePicker() string {
 	return b.String()
 }
 
+func (m model) renderCoAuthorPicker() string {
+	indent := indentStyle()
+	var b strings.Builder
+
+	b.WriteString("\n")
+	b.WriteString(indent.Render(sectionTitleStyle().Render("Co-authors")))
+	b.WriteString("\n\n")
+
+	for i, author := range m.cfg.Commit.Trailers.CoAuthors {
+		cursor := "  "
+		style := metaStyle()
+		if i == m.coAuthorCursor {
+			cursor = "> "
+			style = sectionTitleStyle()
+		}
+		check := "[ ]"
+		if slices.Contains(m.trailers, commit.CoAuthorTrailer(author)) {
+			check = "[x]"
+		}
+		b.WriteString(indent.Render(cursor + style.Render(check+" "+author)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	keys := []struct{ key, desc string }{
+		{"↑/k", "up"}, {"↓/j", "down"}, {"space", "toggle"}, {"enter", "done"},
+	}
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, footerKeyStyle().Render(k.key)+" "+footerDescStyle().Render(k.desc))
+	}
+	b.WriteString(indent.Render(strings.Join(parts, "  ")))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
 func (m model) renderFilePicker() string {
 	indent := indentStyle()
 	var b strings.Builder
@@ -446,7 +591,7 @@ func (m model) renderFilePicker() string {
 			statusIcon = "~"
 		case "D":
 			statusIcon = "-"
-		case "R":
+		case "R", "C":
 			statusIcon = ">"
 		default:
 			statusIcon = "?"
@@ -477,8 +622,20 @@ func (m model) renderError() string {
 
 	b.WriteString("\n")
 	b.WriteString(indent.Render(errorStyle().Render("Error: "+m.err.Error())))
-	b.WriteString("\n\n")
-	b.WriteString(indent.Render(footerKeyStyle().Render("q")+" "+footerDescStyle().Render("quit")))
+	b.WriteString("\n")
+	if hint := backend.Explain(m.err); hint != "" {
+		b.WriteString(indent.Render(metaStyle().Render(hint)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	footer := footerKeyStyle().Render("q") + " " + footerDescStyle().Render("quit")
+	if m.retry != nil {
+		footer = footerKeyStyle().Render("r") + " " + footerDescStyle().Render("retry") + "  " + footer
+	}
+	if m.canGoOffline() {
+		footer = footerKeyStyle().Render("o") + " " + footerDescStyle().Render("offline message") + "  " + footer
+	}
+	b.WriteString(indent.Render(footer))
 	b.WriteString("\n")
 	return b.String()
 }
@@ -505,7 +662,7 @@ func (m model) renderStagedFiles() string {
 		case "D":
 			icon = "-"
 			style = fileDeletedStyle()
-		case "R":
+		case "R", "C":
 			icon = ">"
 			style = fileRenamedStyle()
 		default:
@@ -528,6 +685,7 @@ func (m model) renderCommitMessage() string {
 	b.WriteString("\n")
 	b.WriteString(indent.Render(commitMessageStyle().Render(m.commitMessage)))
 	b.WriteString("\n")
+	b.WriteString(m.renderTrailers())
 
 	firstLine := m.commitMessage
 	if idx := strings.Index(m.commitMessage, "\n"); idx >= 0 {
@@ -542,6 +700,47 @@ func (m model) renderCommitMessage() string {
 	return b.String()
 }
 
+// renderTrailers lists the trailers that will be added to the message.
+func (m model) renderTrailers() string {
+	if len(m.trailers) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n")
+	for _, t := range m.trailers {
+		b.WriteString(indentStyle().Render(commitMessageStyle().Render(metaStyle().Render(t))))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderAmendMessages shows HEAD's current message next to the proposed
+// one, stacked when the terminal is too narrow for two columns.
+func (m model) renderAmendMessages() string {
+	indent := indentStyle()
+	column := func(title, message string, width int) string {
+		body := commitMessageStyle().Width(width).Render(message)
+		return sectionTitleStyle().Render(title) + "\n" + body
+	}
+
+	const gap = 4
+	width := (m.width - 6 - gap) / 2
+	if width < 36 {
+		return indent.Render(column("HEAD Message", m.amend.message, 0)) + "\n\n" +
+			m.renderCommitMessage()
+	}
+	amended := m.commitMessage
+	if len(m.trailers) > 0 {
+		amended += "\n\n" + metaStyle().Render(strings.Join(m.trailers, "\n"))
+	}
+	row := lipgloss.JoinHorizontal(lipgloss.Top,
+		column("HEAD Message", m.amend.message, width),
+		strings.Repeat(" ", gap),
+		column("Amended Message", amended, width),
+	)
+	return indent.Render(row) + "\n"
+}
+
 func (m model) renderStatus() string {
 	indent := indentStyle()
 	var style lipgloss.Style
@@ -565,7 +764,8 @@ func (m model) renderSplitGenerating() string {
 	b.WriteString(indent.Render(m.loader.View()))
 	b.WriteString("\n")
 	b.WriteString(indent.Render(metaStyle().Render("grouping staged files into logical commits...")))
-	b.WriteString("\n")
+	b.WriteString("\n\n")
+	b.WriteString(m.renderCancelHint())
 	return b.String()
 }
 
@@ -576,6 +776,9 @@ func (m model) renderSplitPlan() string {
 	total := len(m.splitPlan)
 
 	title := fmt.Sprintf("Split plan — %d commit(s)", total)
+	if m.splitCached {
+		title += " — cached"
+	}
 	if m.splitMoveMode {
 		if m.splitMovePickDest {
 			title += " — pick destination group"
@@ -590,7 +793,7 @@ func (m model) renderSplitPlan() string {
 	if m.splitRegenerating {
 		b.WriteString(indent.Render(m.loader.View()))
 		b.WriteString("\n")
-		b.WriteString(indent.Render(metaStyle().Render("regenerating plan...")))
+		b.WriteString(indent.Render(metaStyle().Render("regenerating plan... (esc to cancel)")))
 		b.WriteString("\n\n")
 	}
 
@@ -631,8 +834,8 @@ func (m model) renderSplitPlan() string {
 		b.WriteString("\n")
 
 		if expanded {
-			for fi, f := range g.Files {
-				status := m.stagedStatus(f)
+			for fi, it := range splitItems(g) {
+				status := m.stagedStatus(it.file)
 				var statusStyle lipgloss.Style
 				switch status {
 				case "A":
@@ -641,7 +844,7 @@ func (m model) renderSplitPlan() string {
 					statusStyle = fileModifiedStyle()
 				case "D":
 					statusStyle = fileDeletedStyle()
-				case "R":
+				case "R", "C":
 					statusStyle = fileRenamedStyle()
 				default:
 					statusStyle = metaStyle()
@@ -652,7 +855,15 @@ func (m model) renderSplitPlan() string {
 					marker = splitMoveCursorStyle().Render("▶ ")
 				}
 
-				fileLine := "   " + marker + statusStyle.Render(status+" "+f)
+				fileLine := "   " + marker + statusStyle.Render(status+" "+it.file)
+				if it.hunk > 0 {
+					fd := m.splitDiff[it.file]
+					title := ""
+					if it.hunk <= len(fd.Hunks) {
+						title, _, _ = strings.Cut(fd.Hunks[it.hunk-1], "\n")
+					}
+					fileLine += metaStyle().Render(fmt.Sprintf("  hunk %d/%d %s", it.hunk, len(fd.Hunks), title))
+				}
 				b.WriteString(indent.Render(fileLine))
 				b.WriteString("\n")
 			}
@@ -681,6 +892,7 @@ func (m model) renderSplitPlan() string {
 					{"↓/j", "next file"},
 					{"1-9", "reassign to group"},
 					{"enter", "pick dest"},
+					{"h", "split into hunks"},
 					{"esc", "cancel"},
 				}
 			} else {
@@ -688,6 +900,7 @@ func (m model) renderSplitPlan() string {
 					{"↑/k", "prev file"},
 					{"↓/j", "next file"},
 					{"enter", "pick dest"},
+					{"h", "split into hunks"},
 					{"esc", "cancel"},
 				}
 			}
@@ -701,6 +914,7 @@ func (m model) renderSplitPlan() string {
 			{"m", "move file"},
 			{"r", "regen"},
 			{"f", "regen w/ feedback"},
+			{"P", "request"},
 			{"c", "confirm all"},
 			{"esc/q", "cancel"},
 		}
@@ -724,6 +938,12 @@ func (m model) renderSplitCommitting() string {
 	b.WriteString("\n")
 	b.WriteString(indent.Render(m.loader.View()))
 	b.WriteString("\n")
+	if m.splitAborting {
+		b.WriteString(indent.Render(metaStyle().Render("stopping after the current commit... (ctrl+c to quit now)")))
+	} else {
+		b.WriteString(indent.Render(metaStyle().Render("esc to stop after the current commit")))
+	}
+	b.WriteString("\n")
 	return b.String()
 }
 
@@ -783,7 +1003,11 @@ func (m model) renderSplitFailure() string {
 	f := m.splitFailure
 
 	b.WriteString("\n")
-	b.WriteString(indent.Render(errorStyle().Render("Split stopped — one commit failed")))
+	if f.aborted {
+		b.WriteString(indent.Render(errorStyle().Render("Split stopped")))
+	} else {
+		b.WriteString(indent.Render(errorStyle().Render("Split stopped — one commit failed")))
+	}
 	b.WriteString("\n\n")
 
 	committed := len(f.committedHashes)
@@ -805,7 +1029,7 @@ func (m model) renderSplitFailure() string {
 		b.WriteString("\n\n")
 	}
 
-	if f.failedIndex >= 0 && f.failedIndex < len(m.splitPlan) {
+	if !f.aborted && f.failedIndex >= 0 && f.failedIndex < len(m.splitPlan) {
 		g := m.splitPlan[f.failedIndex]
 		firstLine := g.Message
 		if idx := strings.Index(firstLine, "\n"); idx >= 0 {
@@ -845,7 +1069,21 @@ func (m model) renderSplitFailure() string {
 		b.WriteString("\n")
 	}
 
-	b.WriteString(indent.Render(footerKeyStyle().Render("enter/q") + " " + footerDescStyle().Render("quit")))
+	if f.snapshot != "" && !m.splitRestored {
+		b.WriteString(indent.Render(metaStyle().Render(fmt.Sprintf("The index from before the split is saved as tree %s (git read-tree %s).", f.snapshot, f.snapshot))))
+		b.WriteString("\n")
+		b.WriteString(indent.Render(metaStyle().Render("Fix the problem and run diny commit --continue-split, or undo the split with diny commit --abort-split.")))
+		b.WriteString("\n\n")
+	}
+	if m.statusMessage != "" {
+		b.WriteString(m.renderStatus())
+	}
+
+	footer := footerKeyStyle().Render("enter/q") + " " + footerDescStyle().Render("quit")
+	if f.snapshot != "" && !m.splitRestored {
+		footer = footerKeyStyle().Render("r") + " " + footerDescStyle().Render("restore original index") + "  " + footer
+	}
+	b.WriteString(indent.Render(footer))
 	b.WriteString("\n")
 
 	return b.String()
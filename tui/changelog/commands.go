@@ -1,6 +1,7 @@
 package changelog
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,6 +13,7 @@ import (
 	"github.com/dinoDanic/diny/config"
 	"github.com/dinoDanic/diny/git"
 	"github.com/dinoDanic/diny/groq"
+	"github.com/dinoDanic/diny/summarize"
 )
 
 func loadRepoInfo() tea.Cmd {
@@ -39,8 +41,20 @@ func loadRefs(mode string) tea.Cmd {
 	}
 }
 
-func doGenerate(olderRef, newerRef string, cfg *config.Config) tea.Cmd {
+func waitForProgress(ch <-chan string) tea.Cmd {
 	return func() tea.Msg {
+		text, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return progressMsg{ch: ch, text: text}
+	}
+}
+
+func doGenerate(ctx context.Context, olderRef, newerRef string, cfg *config.Config, progressCh chan string) tea.Cmd {
+	return func() tea.Msg {
+		defer close(progressCh)
+
 		commits, err := git.GetCommitsBetweenRefs(olderRef, newerRef)
 		if err != nil {
 			return errMsg{err: fmt.Errorf("failed to get commits: %w", err)}
@@ -54,11 +68,24 @@ func doGenerate(olderRef, newerRef string, cfg *config.Config) tea.Cmd {
 			return errMsg{err: fmt.Errorf("failed to get diff: %w", err)}
 		}
 
+		diff, err = summarize.Condense(ctx, diff, cfg, func(done, total int) {
+			progressCh <- fmt.Sprintf("large diff — summarised %d/%d parts...", done, total)
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to summarise large diff: %w", err)}
+		}
+
 		repoName := git.GetRepoName()
 		gitName := git.GetGitName()
 		prompt := buildChangelogPrompt(repoName, gitName, olderRef, newerRef, commits, diff)
 
-		result, err := groq.CreateChangelogWithGroq(prompt, cfg)
+		result, err := groq.CreateChangelogWithGroq(ctx, prompt, cfg)
+		if ctx.Err() != nil {
+			return nil
+		}
 		if err != nil {
 			return errMsg{err: fmt.Errorf("failed to generate changelog: %w", err)}
 		}
@@ -66,7 +93,7 @@ func doGenerate(olderRef, newerRef string, cfg *config.Config) tea.Cmd {
 	}
 }
 
-func doRegenerate(prompt string, cfg *config.Config, previousResults []string) tea.Cmd {
+func doRegenerate(ctx context.Context, prompt string, cfg *config.Config, previousResults []string) tea.Cmd {
 	return func() tea.Msg {
 		modifiedPrompt := prompt
 		if len(previousResults) > 0 {
@@ -79,7 +106,10 @@ func doRegenerate(prompt string, cfg *config.Config, previousResults []string) t
 			modifiedPrompt += "\n\nPlease provide an alternative changelog with a different approach."
 		}
 
-		result, err := groq.CreateChangelogWithGroq(modifiedPrompt, cfg)
+		result, err := groq.CreateChangelogWithGroq(ctx, modifiedPrompt, cfg)
+		if ctx.Err() != nil {
+			return nil
+		}
 		if err != nil {
 			return errMsg{err: fmt.Errorf("failed to regenerate changelog: %w", err)}
 		}
@@ -111,10 +141,6 @@ func buildChangelogPrompt(repoName, gitName, olderRef, newerRef string, commits
 	for i, c := range commits {
 		commitLines[i] = "- " + c
 	}
-	diffSummary := diff
-	if len(diffSummary) > 4000 {
-		diffSummary = diffSummary[:4000] + "\n... (diff truncated)"
-	}
 	return fmt.Sprintf(`Generate a changelog for the following repository changes.
 
 Repository: %s
@@ -135,7 +161,7 @@ Use sub-bullets for individual changes. Group logically if possible.
 Keep it concise and human-readable.`,
 		repoName, gitName, olderRef, newerRef,
 		len(commits), strings.Join(commitLines, "\n"),
-		diffSummary,
+		diff,
 	)
 }
 
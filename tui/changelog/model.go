@@ -1,6 +1,10 @@
 package changelog
 
 import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/dinoDanic/diny/config"
 	"github.com/dinoDanic/diny/git"
 	"github.com/dinoDanic/diny/tui/loader"
@@ -20,6 +24,25 @@ const (
 	stateError
 )
 
+var stateNames = map[state]string{
+	stateModeSelect:     "mode-select",
+	stateLoadingRefs:    "loading-refs",
+	stateSelectNewerRef: "select-newer-ref",
+	stateSelectOlderRef: "select-older-ref",
+	stateGenerating:     "generating",
+	stateResults:        "results",
+	stateRegenerating:   "regenerating",
+	stateNoCommits:      "no-commits",
+	stateError:          "error",
+}
+
+func (s state) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("state(%d)", int(s))
+}
+
 const listPageSize = 10
 
 // Messages
@@ -39,6 +62,12 @@ type changelogReadyMsg struct {
 	prompt string
 }
 
+// progressMsg reports a finished chunk while a large diff is summarised.
+type progressMsg struct {
+	ch   <-chan string
+	text string
+}
+
 type noCommitsMsg struct{}
 
 type copiedMsg struct{}
@@ -81,6 +110,8 @@ type model struct {
 	previousResults []string
 
 	loader loader.Model
+	cancel context.CancelFunc           // aborts the in-flight generation, if any
+	retry  func(model) (model, tea.Cmd) // re-runs the failed generation from stateError
 
 	statusMessage string
 	statusIsError bool
@@ -96,3 +127,20 @@ func newModel(cfg *config.Config, version string) model {
 		loader:  loader.New(loader.GeneratingMessages),
 	}
 }
+
+// startRequest cancels any in-flight generation and returns a fresh context
+// for the next one.
+func (m *model) startRequest() context.Context {
+	m.cancelRequest()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	return ctx
+}
+
+func (m *model) cancelRequest() {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	m.retry = nil
+}
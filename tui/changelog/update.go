@@ -1,9 +1,11 @@
 package changelog
 
 import (
+	"context"
 	"fmt"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dinoDanic/diny/logging"
 	"github.com/dinoDanic/diny/tui/loader"
 )
 
@@ -14,7 +16,20 @@ func (m model) Init() tea.Cmd {
 	)
 }
 
+// Update logs state transitions around update, which does the work.
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if n, ok := next.(model); ok && n.state != m.state {
+		args := []any{"tui", "changelog", "from", m.state, "to", n.state}
+		if n.state == stateError && n.err != nil {
+			args = append(args, "error", logging.Scrub(n.err.Error()))
+		}
+		logging.Info("state", args...)
+	}
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -45,12 +60,27 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case changelogReadyMsg:
+		if m.state != stateGenerating && m.state != stateRegenerating {
+			// Finished just as it was cancelled.
+			return m, nil
+		}
+		m.cancel = nil
+		m.retry = nil
 		m.result = msg.result
 		m.prompt = msg.prompt
 		m.state = stateResults
 		return m, nil
 
+	case progressMsg:
+		// Keep draining a cancelled run's progress, but only show the live one.
+		if m.state == stateGenerating {
+			m.loader.SetMessage(msg.text)
+		}
+		return m, waitForProgress(msg.ch)
+
 	case noCommitsMsg:
+		m.cancel = nil
+		m.retry = nil
 		m.state = stateNoCommits
 		return m, nil
 
@@ -65,6 +95,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case errMsg:
+		m.cancel = nil
 		m.err = msg.err
 		m.state = stateError
 		return m, nil
@@ -166,9 +197,10 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		case "enter":
 			m.olderRef = m.selectedValue()
 			m.rangeLabel = fmt.Sprintf("%s → %s", m.olderRef, m.newerRef)
-			m.state = stateGenerating
-			m.loader = loader.New(loader.GeneratingMessages)
-			return m, tea.Batch(m.loader.Tick, doGenerate(m.olderRef, m.newerRef, m.cfg))
+			return m.request(stateGenerating, func(ctx context.Context) tea.Cmd {
+				ch := make(chan string, 16)
+				return tea.Batch(doGenerate(ctx, m.olderRef, m.newerRef, m.cfg, ch), waitForProgress(ch))
+			})
 		case "esc":
 			m.listCursor = 0
 			m.listOffset = 0
@@ -178,6 +210,32 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case stateGenerating:
+		switch key {
+		case "esc":
+			m.cancelRequest()
+			m.state = stateSelectOlderRef
+		case "q", "ctrl+c":
+			m.cancelRequest()
+			return m, tea.Quit
+		}
+		return m, nil
+
+	case stateRegenerating:
+		switch key {
+		case "esc":
+			m.cancelRequest()
+			// Drop the result that was queued as "previous" for this attempt.
+			if n := len(m.previousResults); n > 0 {
+				m.previousResults = m.previousResults[:n-1]
+			}
+			m.state = stateResults
+		case "q", "ctrl+c":
+			m.cancelRequest()
+			return m, tea.Quit
+		}
+		return m, nil
+
 	case stateResults:
 		switch key {
 		case "c":
@@ -186,9 +244,9 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, doSave(m.result, m.rangeLabel)
 		case "r":
 			m.previousResults = append(m.previousResults, m.result)
-			m.state = stateRegenerating
-			m.loader = loader.New(loader.GeneratingMessages)
-			return m, tea.Batch(m.loader.Tick, doRegenerate(m.prompt, m.cfg, m.previousResults))
+			return m.request(stateRegenerating, func(ctx context.Context) tea.Cmd {
+				return doRegenerate(ctx, m.prompt, m.cfg, m.previousResults)
+			})
 		case "n":
 			return m.resetToModeSelect()
 		case "q", "ctrl+c":
@@ -207,6 +265,11 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case stateError:
 		switch key {
+		case "r":
+			if m.retry != nil {
+				m.err = nil
+				return m.retry(m)
+			}
 		case "q", "ctrl+c":
 			return m, tea.Quit
 		}
@@ -246,6 +309,16 @@ func (m model) selectedValue() string {
 	return ""
 }
 
+// request enters a loading state and starts gen with a fresh context. gen
+// is kept so the retry key in stateError can run the same request again.
+func (m model) request(next state, gen func(ctx context.Context) tea.Cmd) (model, tea.Cmd) {
+	m.state = next
+	m.loader = loader.New(loader.GeneratingMessages)
+	ctx := m.startRequest()
+	m.retry = func(m model) (model, tea.Cmd) { return m.request(next, gen) }
+	return m, tea.Batch(m.loader.Tick, gen(ctx))
+}
+
 func (m model) resetToModeSelect() (tea.Model, tea.Cmd) {
 	m.state = stateModeSelect
 	m.modeCursor = 0
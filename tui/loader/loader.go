@@ -70,6 +70,11 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	return m, cmd
 }
 
+// SetMessage replaces the spinner text, e.g. with progress on a long job.
+func (m *Model) SetMessage(message string) {
+	m.message = message
+}
+
 func (m Model) View() string {
 	t := ui.GetCurrentTheme()
 	style := lipgloss.NewStyle().Foreground(t.PrimaryForeground)
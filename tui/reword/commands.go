@@ -0,0 +1,58 @@
+package reword
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dinoDanic/diny/commit"
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/git"
+	"github.com/dinoDanic/diny/summarize"
+)
+
+func loadRepoInfo() tea.Cmd {
+	return func() tea.Msg {
+		repoName := git.GetRepoName()
+		branchName, _ := git.GetCurrentBranch()
+		return repoInfoMsg{
+			repoName:   repoName,
+			branchName: branchName,
+		}
+	}
+}
+
+// doGenerate writes a message for commit hash from its own diff alone,
+// steering away from previous proposals on a regenerate. The branch's
+// issue key is added as for any generated message.
+func doGenerate(ctx context.Context, idx int, hash string, previous []string, cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		diff, err := git.GetCommitDiff(hash)
+		if err != nil {
+			return generatedMsg{idx: idx, err: err}
+		}
+		prompt, err := summarize.Condense(ctx, diff, cfg, nil)
+		if err != nil {
+			return generatedMsg{idx: idx, err: fmt.Errorf("failed to summarise large diff: %w", err)}
+		}
+		msg, err := commit.CreateCommitMessage(ctx, commit.RegeneratePrompt(prompt, previous), cfg)
+		if err != nil {
+			return generatedMsg{idx: idx, err: err}
+		}
+		key := commit.BranchIssueKey(cfg)
+		msg = commit.ApplyIssue(msg, cfg.Commit.Issue, key)
+		if t := commit.IssueTrailer(cfg.Commit.Issue, key); t != "" {
+			if msg, err = commit.WithTrailers(msg, []string{t}); err != nil {
+				return generatedMsg{idx: idx, err: err}
+			}
+		}
+		return generatedMsg{idx: idx, message: msg}
+	}
+}
+
+func doRewrite(plan *commit.RewordPlan, messages []string) tea.Cmd {
+	return func() tea.Msg {
+		changed, err := plan.Apply(messages)
+		return rewriteDoneMsg{changed: changed, err: err}
+	}
+}
@@ -0,0 +1,133 @@
+package reword
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/dinoDanic/diny/commit"
+	"github.com/dinoDanic/diny/config"
+	"github.com/dinoDanic/diny/tui/loader"
+)
+
+type state int
+
+const (
+	stateList state = iota
+	stateEditing
+	stateRewriting
+	stateSuccess
+	stateError
+)
+
+var stateNames = map[state]string{
+	stateList:      "list",
+	stateEditing:   "editing",
+	stateRewriting: "rewriting",
+	stateSuccess:   "success",
+	stateError:     "error",
+}
+
+func (s state) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("state(%d)", int(s))
+}
+
+// Messages
+
+// startMsg kicks off generation once the program runs.
+type startMsg struct{}
+
+type repoInfoMsg struct {
+	repoName   string
+	branchName string
+}
+
+type generatedMsg struct {
+	idx     int
+	message string
+	err     error
+}
+
+type rewriteDoneMsg struct {
+	changed int
+	err     error
+}
+
+// Model
+
+// proposal is one commit of the range and what to do with its message.
+type proposal struct {
+	message    string // the proposed message, once generated
+	previous   []string
+	keep       bool // keep the original message
+	generating bool
+	err        error
+}
+
+type model struct {
+	cfg     *config.Config
+	version string
+	state   state
+	width   int
+
+	repoName   string
+	branchName string
+
+	plan      *commit.RewordPlan
+	proposals []proposal
+	cursor    int
+	queue     []int // proposals waiting to be generated, one at a time
+	ctx       context.Context
+	cancel    context.CancelFunc
+
+	textarea textarea.Model
+	changed  int
+	status   string
+	err      error
+
+	loader loader.Model
+}
+
+func newModel(cfg *config.Config, version string, plan *commit.RewordPlan) model {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := model{
+		ctx:       ctx,
+		cancel:    cancel,
+		cfg:       cfg,
+		version:   version,
+		state:     stateList,
+		plan:      plan,
+		proposals: make([]proposal, len(plan.Commits)),
+		loader:    loader.New(loader.GeneratingMessages),
+	}
+	for i, c := range plan.Commits {
+		// Merges are carried through the rebase untouched.
+		if c.IsMerge() {
+			m.proposals[i].keep = true
+			continue
+		}
+		m.queue = append(m.queue, i)
+	}
+	return m
+}
+
+// message is what commit i will end up with.
+func (m model) message(i int) string {
+	p := m.proposals[i]
+	if p.keep || p.message == "" {
+		return m.plan.Commits[i].Message
+	}
+	return p.message
+}
+
+func (m model) generating() bool {
+	for _, p := range m.proposals {
+		if p.generating {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package reword
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dinoDanic/diny/commit"
+	"github.com/dinoDanic/diny/config"
+)
+
+// Run shows a proposal for every commit of plan and rewrites the branch
+// with the accepted ones.
+func Run(cfg *config.Config, version string, plan *commit.RewordPlan) {
+	m := newModel(cfg, version, plan)
+	p := tea.NewProgram(m) // no alt-screen — inline rendering
+	if _, err := p.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,195 @@
+package reword
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dinoDanic/diny/logging"
+	"github.com/dinoDanic/diny/tui/loader"
+)
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(
+		m.loader.Tick,
+		loadRepoInfo(),
+		tea.WindowSize(),
+		func() tea.Msg { return startMsg{} },
+	)
+}
+
+// Update logs state transitions around update, which does the work.
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if n, ok := next.(model); ok && n.state != m.state {
+		args := []any{"tui", "reword", "from", m.state, "to", n.state}
+		if n.state == stateError && n.err != nil {
+			args = append(args, "error", logging.Scrub(n.err.Error()))
+		}
+		logging.Info("state", args...)
+	}
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+
+	case repoInfoMsg:
+		m.repoName = msg.repoName
+		m.branchName = msg.branchName
+		return m, nil
+
+	case startMsg:
+		return m.generateNext()
+
+	case generatedMsg:
+		p := &m.proposals[msg.idx]
+		p.generating = false
+		p.err = msg.err
+		if msg.err == nil {
+			p.message = strings.TrimSpace(msg.message)
+		}
+		return m.generateNext()
+
+	case rewriteDoneMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			m.state = stateError
+			return m, nil
+		}
+		m.changed = msg.changed
+		m.state = stateSuccess
+		return m, tea.Quit
+	}
+
+	if m.state == stateEditing {
+		var cmd tea.Cmd
+		m.textarea, cmd = m.textarea.Update(msg)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.loader, cmd = m.loader.Update(msg)
+	return m, cmd
+}
+
+// generateNext starts the next queued proposal unless one is in flight;
+// proposals are generated one at a time to go easy on the backend.
+func (m model) generateNext() (model, tea.Cmd) {
+	if m.generating() || len(m.queue) == 0 {
+		return m, nil
+	}
+	idx := m.queue[0]
+	m.queue = m.queue[1:]
+	m.proposals[idx].generating = true
+	m.proposals[idx].err = nil
+	m.loader = loader.New(loader.GeneratingMessages)
+	return m, tea.Batch(m.loader.Tick, doGenerate(m.ctx, idx, m.plan.Commits[idx].Hash, m.proposals[idx].previous, m.cfg))
+}
+
+func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.state {
+	case stateList:
+		return m.handleListKey(msg)
+	case stateEditing:
+		return m.handleEditingKey(msg)
+	case stateError, stateSuccess:
+		if msg.String() == "q" || msg.String() == "ctrl+c" || msg.String() == "enter" {
+			return m, tea.Quit
+		}
+	case stateRewriting:
+		// The rebase must not be interrupted halfway.
+	}
+	return m, nil
+}
+
+func (m model) handleListKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.status = ""
+	merge := m.plan.Commits[m.cursor].IsMerge()
+
+	switch msg.String() {
+	case "q", "ctrl+c", "esc":
+		m.cancel()
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.proposals)-1 {
+			m.cursor++
+		}
+	case " ":
+		if merge {
+			m.status = "Merge commits keep their message"
+			return m, nil
+		}
+		m.proposals[m.cursor].keep = !m.proposals[m.cursor].keep
+	case "r":
+		if merge {
+			m.status = "Merge commits keep their message"
+			return m, nil
+		}
+		p := &m.proposals[m.cursor]
+		if p.generating || slices.Contains(m.queue, m.cursor) {
+			return m, nil
+		}
+		if p.message != "" {
+			p.previous = append(p.previous, p.message)
+		}
+		p.keep = false
+		m.queue = append(m.queue, m.cursor)
+		return m.generateNext()
+	case "e":
+		if merge {
+			m.status = "Merge commits keep their message"
+			return m, nil
+		}
+		if m.proposals[m.cursor].generating {
+			return m, nil
+		}
+		m.textarea = textarea.New()
+		m.textarea.SetValue(m.message(m.cursor))
+		m.textarea.SetHeight(8)
+		m.textarea.SetWidth(72)
+		m.textarea.Focus()
+		m.state = stateEditing
+		return m, m.textarea.Cursor.BlinkCmd()
+	case "enter":
+		if m.generating() || len(m.queue) > 0 {
+			m.status = "Still generating — wait for every proposal, or press space to keep a message"
+			return m, nil
+		}
+		messages := make([]string, len(m.proposals))
+		for i := range m.proposals {
+			messages[i] = m.message(i)
+		}
+		m.state = stateRewriting
+		m.loader = loader.New(loader.CommittingMessages)
+		return m, tea.Batch(m.loader.Tick, doRewrite(m.plan, messages))
+	}
+	return m, nil
+}
+
+func (m model) handleEditingKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		if edited := strings.TrimSpace(m.textarea.Value()); edited != "" && edited != m.message(m.cursor) {
+			m.proposals[m.cursor].message = edited
+			m.proposals[m.cursor].keep = false
+		}
+		m.state = stateList
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textarea, cmd = m.textarea.Update(msg)
+	return m, cmd
+}
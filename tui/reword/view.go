@@ -0,0 +1,143 @@
+package reword
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dinoDanic/diny/tui/shared"
+)
+
+func (m model) View() string {
+	var b strings.Builder
+
+	b.WriteString("\n")
+	b.WriteString(shared.RenderHeader(m.version, m.repoName, m.branchName, m.width))
+	b.WriteString("\n")
+
+	switch m.state {
+	case stateList:
+		b.WriteString(m.renderList())
+	case stateEditing:
+		b.WriteString(m.renderEditing())
+	case stateRewriting:
+		b.WriteString(m.renderRewriting())
+	case stateSuccess:
+		b.WriteString(m.renderSuccess())
+	case stateError:
+		b.WriteString(m.renderError())
+	}
+
+	return b.String()
+}
+
+func (m model) renderList() string {
+	indent := indentStyle()
+	var b strings.Builder
+
+	b.WriteString("\n")
+	b.WriteString(indent.Render(sectionTitleStyle().Render(fmt.Sprintf("Reword %s (%d commits)", m.plan.Range, len(m.plan.Commits)))))
+	b.WriteString("\n\n")
+
+	for i, c := range m.plan.Commits {
+		p := m.proposals[i]
+		marker := "  "
+		if i == m.cursor {
+			marker = cursorStyle().Render("> ")
+		}
+
+		var next string
+		switch {
+		case c.IsMerge():
+			next = metaStyle().Render("merge — kept")
+		case p.generating:
+			next = metaStyle().Render(m.loader.View())
+		case p.err != nil:
+			next = errorStyle().Render("failed: " + p.err.Error())
+		case p.keep:
+			next = metaStyle().Render("kept")
+		case p.message == "":
+			next = metaStyle().Render("queued")
+		default:
+			next = newSubjectStyle().Render(subject(p.message))
+		}
+
+		line := fmt.Sprintf("%s%s %s  →  %s", marker, metaStyle().Render(c.Hash[:min(7, len(c.Hash))]), c.Subject(), next)
+		b.WriteString(indent.Render(line))
+		b.WriteString("\n")
+	}
+
+	if msg := m.message(m.cursor); msg != m.plan.Commits[m.cursor].Message {
+		b.WriteString("\n")
+		b.WriteString(indent.Render(sectionTitleStyle().Render("New Message")))
+		b.WriteString("\n")
+		b.WriteString(indent.Render(commitMessageStyle().Render(msg)))
+		b.WriteString("\n")
+	}
+
+	if m.status != "" {
+		b.WriteString("\n")
+		b.WriteString(indent.Render(warningStyle().Render(m.status)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.renderFooter([][2]string{
+		{"enter", "rewrite"}, {"e", "edit"}, {"r", "regen"}, {"space", "keep/new"}, {"↑/↓", "move"}, {"q", "quit"},
+	}))
+	return b.String()
+}
+
+func (m model) renderEditing() string {
+	indent := indentStyle()
+	var b strings.Builder
+
+	c := m.plan.Commits[m.cursor]
+	b.WriteString("\n")
+	b.WriteString(indent.Render(sectionTitleStyle().Render(fmt.Sprintf("Edit message of %s", c.Hash[:min(7, len(c.Hash))]))))
+	b.WriteString("\n")
+	b.WriteString(indent.Render(metaStyle().Render("was: " + c.Subject())))
+	b.WriteString("\n")
+	b.WriteString(indent.Render(m.textarea.View()))
+	b.WriteString("\n\n")
+	b.WriteString(indent.Render(metaStyle().Render("esc accept")))
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (m model) renderRewriting() string {
+	return "\n" + indentStyle().Render(m.loader.View()) + "\n"
+}
+
+func (m model) renderSuccess() string {
+	indent := indentStyle()
+	line := "Nothing changed — every message was kept."
+	if m.changed > 0 {
+		line = fmt.Sprintf("Reworded %d commit(s). diny undo takes it back.", m.changed)
+	}
+	return "\n" + indent.Render(successBigStyle().Render(line)) + "\n\n"
+}
+
+func (m model) renderError() string {
+	indent := indentStyle()
+	var b strings.Builder
+
+	b.WriteString("\n")
+	b.WriteString(indent.Render(errorStyle().Render("Error: " + m.err.Error())))
+	b.WriteString("\n")
+	b.WriteString(indent.Render(metaStyle().Render("The rebase was aborted; the branch is unchanged.")))
+	b.WriteString("\n\n")
+	b.WriteString(m.renderFooter([][2]string{{"q", "quit"}}))
+	return b.String()
+}
+
+func (m model) renderFooter(keys [][2]string) string {
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, footerKeyStyle().Render(k[0])+" "+footerDescStyle().Render(k[1]))
+	}
+	return indentStyle().Render(strings.Join(parts, "  ")) + "\n"
+}
+
+func subject(message string) string {
+	return strings.SplitN(message, "\n", 2)[0]
+}
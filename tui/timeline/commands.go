@@ -1,6 +1,7 @@
 package timeline
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -25,7 +26,7 @@ func loadRepoInfo() tea.Cmd {
 	}
 }
 
-func fetchAndGenerate(dateChoice, startDate, endDate, dateRange string, cfg *config.Config) tea.Cmd {
+func fetchAndGenerate(ctx context.Context, dateChoice, startDate, endDate, dateRange string, cfg *config.Config) tea.Cmd {
 	return func() tea.Msg {
 		var commits []string
 		var err error
@@ -48,7 +49,10 @@ func fetchAndGenerate(dateChoice, startDate, endDate, dateRange string, cfg *con
 		}
 
 		prompt := fmt.Sprintf("Timeline: %s\nCommits:\n%s", dateRange, strings.Join(commits, "\n"))
-		analysis, err := groq.CreateTimelineWithGroq(prompt, cfg)
+		analysis, err := groq.CreateTimelineWithGroq(ctx, prompt, cfg)
+		if ctx.Err() != nil {
+			return nil
+		}
 		if err != nil {
 			return errMsg{err: fmt.Errorf("failed to generate analysis: %w", err)}
 		}
@@ -61,7 +65,7 @@ func fetchAndGenerate(dateChoice, startDate, endDate, dateRange string, cfg *con
 	}
 }
 
-func doRegenerate(fullPrompt string, cfg *config.Config, previousAnalyses []string) tea.Cmd {
+func doRegenerate(ctx context.Context, fullPrompt string, cfg *config.Config, previousAnalyses []string) tea.Cmd {
 	return func() tea.Msg {
 		modifiedPrompt := fullPrompt
 		if len(previousAnalyses) > 0 {
@@ -74,7 +78,10 @@ func doRegenerate(fullPrompt string, cfg *config.Config, previousAnalyses []stri
 			modifiedPrompt += "\n\nPlease provide an alternative analysis with a different approach or focus."
 		}
 
-		analysis, err := groq.CreateTimelineWithGroq(modifiedPrompt, cfg)
+		analysis, err := groq.CreateTimelineWithGroq(ctx, modifiedPrompt, cfg)
+		if ctx.Err() != nil {
+			return nil
+		}
 		if err != nil {
 			return errMsg{err: fmt.Errorf("failed to regenerate analysis: %w", err)}
 		}
@@ -87,14 +94,17 @@ func doRegenerate(fullPrompt string, cfg *config.Config, previousAnalyses []stri
 	}
 }
 
-func doFeedback(fullPrompt, current, feedback string, cfg *config.Config) tea.Cmd {
+func doFeedback(ctx context.Context, fullPrompt, current, feedback string, cfg *config.Config) tea.Cmd {
 	return func() tea.Msg {
 		modifiedPrompt := fullPrompt + fmt.Sprintf(
 			"\n\nCurrent analysis:\n%s\n\nUser feedback: %s\n\nPlease generate a new analysis that addresses the user's feedback.",
 			current, feedback,
 		)
 
-		analysis, err := groq.CreateTimelineWithGroq(modifiedPrompt, cfg)
+		analysis, err := groq.CreateTimelineWithGroq(ctx, modifiedPrompt, cfg)
+		if ctx.Err() != nil {
+			return nil
+		}
 		if err != nil {
 			return errMsg{err: fmt.Errorf("failed to refine analysis: %w", err)}
 		}
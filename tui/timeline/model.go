@@ -1,7 +1,10 @@
 package timeline
 
 import (
+	"context"
+
 	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/dinoDanic/diny/config"
 	"github.com/dinoDanic/diny/tui/loader"
 )
@@ -70,6 +73,8 @@ type model struct {
 	fullPrompt       string
 
 	loader           loader.Model
+	cancel           context.CancelFunc           // aborts the in-flight generation, if any
+	retry            func(model) (model, tea.Cmd) // re-runs the failed generation from stateError
 	textinput        textinput.Model
 	picker           datePicker
 	savedStartPicker datePicker // preserved start picker when editing end date
@@ -92,3 +97,20 @@ func newModel(cfg *config.Config, version string) model {
 		textinput: ti,
 	}
 }
+
+// startRequest cancels any in-flight generation and returns a fresh context
+// for the next one.
+func (m *model) startRequest() context.Context {
+	m.cancelRequest()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	return ctx
+}
+
+func (m *model) cancelRequest() {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	m.retry = nil
+}
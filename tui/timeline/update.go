@@ -1,6 +1,7 @@
 package timeline
 
 import (
+	"context"
 	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -27,6 +28,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case analysisReadyMsg:
+		if m.state != stateFetching && m.state != stateRegenerating {
+			// Finished just as it was cancelled.
+			return m, nil
+		}
+		m.cancel = nil
+		m.retry = nil
 		if msg.commits != nil {
 			m.commits = msg.commits
 		}
@@ -36,6 +43,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case noCommitsMsg:
+		m.cancel = nil
+		m.retry = nil
 		m.state = stateNoCommits
 		return m, nil
 
@@ -50,6 +59,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case errMsg:
+		m.cancel = nil
 		m.err = msg.err
 		m.state = stateError
 		return m, nil
@@ -123,9 +133,9 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			date := m.picker.dateString()
 			m.startDate = date
 			m.dateRange = date
-			m.state = stateFetching
-			m.loader = loader.New(loader.GeneratingMessages)
-			return m, tea.Batch(m.loader.Tick, fetchAndGenerate(m.dateChoice, m.startDate, m.endDate, m.dateRange, m.cfg))
+			return m.request(stateFetching, func(ctx context.Context) tea.Cmd {
+				return fetchAndGenerate(ctx, m.dateChoice, m.startDate, m.endDate, m.dateRange, m.cfg)
+			})
 		case "esc":
 			m.state = stateDateSelect
 			return m, nil
@@ -195,9 +205,9 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 			m.dateRange = m.startDate + " to " + m.endDate
-			m.state = stateFetching
-			m.loader = loader.New(loader.GeneratingMessages)
-			return m, tea.Batch(m.loader.Tick, fetchAndGenerate(m.dateChoice, m.startDate, m.endDate, m.dateRange, m.cfg))
+			return m.request(stateFetching, func(ctx context.Context) tea.Cmd {
+				return fetchAndGenerate(ctx, m.dateChoice, m.startDate, m.endDate, m.dateRange, m.cfg)
+			})
 		case "esc":
 			m.picker = m.savedStartPicker
 			m.state = statePickStartDate
@@ -207,6 +217,32 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case stateFetching:
+		switch key {
+		case "esc":
+			m.cancelRequest()
+			m.state = stateDateSelect
+			return m, nil
+		case "q", "ctrl+c":
+			m.cancelRequest()
+			return m, tea.Quit
+		}
+
+	case stateRegenerating:
+		switch key {
+		case "esc":
+			m.cancelRequest()
+			// Drop the analysis that was queued as "previous" for this attempt.
+			if n := len(m.previousAnalyses); n > 0 {
+				m.previousAnalyses = m.previousAnalyses[:n-1]
+			}
+			m.state = stateResults
+			return m, nil
+		case "q", "ctrl+c":
+			m.cancelRequest()
+			return m, tea.Quit
+		}
+
 	case stateResults:
 		switch key {
 		case "c":
@@ -215,9 +251,9 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, doSave(m.analysis, m.dateRange)
 		case "r":
 			m.previousAnalyses = append(m.previousAnalyses, m.analysis)
-			m.state = stateRegenerating
-			m.loader = loader.New(loader.GeneratingMessages)
-			return m, tea.Batch(m.loader.Tick, doRegenerate(m.fullPrompt, m.cfg, m.previousAnalyses))
+			return m.request(stateRegenerating, func(ctx context.Context) tea.Cmd {
+				return doRegenerate(ctx, m.fullPrompt, m.cfg, m.previousAnalyses)
+			})
 		case "f":
 			ti := textinput.New()
 			ti.Placeholder = "e.g., focus more on patterns, include statistics..."
@@ -237,9 +273,9 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		case "enter":
 			feedback := m.textinput.Value()
 			m.previousAnalyses = append(m.previousAnalyses, m.analysis)
-			m.state = stateRegenerating
-			m.loader = loader.New(loader.GeneratingMessages)
-			return m, tea.Batch(m.loader.Tick, doFeedback(m.fullPrompt, m.analysis, feedback, m.cfg))
+			return m.request(stateRegenerating, func(ctx context.Context) tea.Cmd {
+				return doFeedback(ctx, m.fullPrompt, m.analysis, feedback, m.cfg)
+			})
 		case "esc":
 			m.state = stateResults
 			return m, nil
@@ -259,6 +295,11 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case stateError:
 		switch key {
+		case "r":
+			if m.retry != nil {
+				m.err = nil
+				return m.retry(m)
+			}
 		case "q", "ctrl+c":
 			return m, tea.Quit
 		}
@@ -289,9 +330,9 @@ func (m model) confirmDateChoice() (tea.Model, tea.Cmd) {
 			m.dateChoice = "range"
 		}
 
-		m.state = stateFetching
-		m.loader = loader.New(loader.GeneratingMessages)
-		return m, tea.Batch(m.loader.Tick, fetchAndGenerate(m.dateChoice, m.startDate, m.endDate, m.dateRange, m.cfg))
+		return m.request(stateFetching, func(ctx context.Context) tea.Cmd {
+			return fetchAndGenerate(ctx, m.dateChoice, m.startDate, m.endDate, m.dateRange, m.cfg)
+		})
 	}
 
 	// Custom selections
@@ -310,6 +351,16 @@ func (m model) confirmDateChoice() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// request enters a loading state and starts gen with a fresh context. gen
+// is kept so the retry key in stateError can run the same request again.
+func (m model) request(next state, gen func(ctx context.Context) tea.Cmd) (model, tea.Cmd) {
+	m.state = next
+	m.loader = loader.New(loader.GeneratingMessages)
+	ctx := m.startRequest()
+	m.retry = func(m model) (model, tea.Cmd) { return m.request(next, gen) }
+	return m, tea.Batch(m.loader.Tick, gen(ctx))
+}
+
 func (m model) resetToDateSelect() (tea.Model, tea.Cmd) {
 	m.state = stateDateSelect
 	m.dateCursor = 0
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/dinoDanic/diny/backend"
 	"github.com/dinoDanic/diny/tui/shared"
 )
 
@@ -71,7 +72,14 @@ func (m model) renderDateSelect() string {
 
 func (m model) renderLoading() string {
 	indent := indentStyle()
-	return indent.Render(m.loader.View()) + "\n"
+	var b strings.Builder
+	b.WriteString(indent.Render(m.loader.View()) + "\n\n")
+	b.WriteString(indent.Render(
+		footerKeyStyle().Render("esc") + " " + footerDescStyle().Render("cancel") + "  " +
+			footerKeyStyle().Render("q") + " " + footerDescStyle().Render("quit"),
+	))
+	b.WriteString("\n")
+	return b.String()
 }
 
 func (m model) renderResults() string {
@@ -153,8 +161,17 @@ func (m model) renderError() string {
 
 	b.WriteString("\n")
 	b.WriteString(indent.Render(errorStyle().Render("Error: " + m.err.Error())))
-	b.WriteString("\n\n")
-	b.WriteString(indent.Render(footerKeyStyle().Render("q") + " " + footerDescStyle().Render("quit")))
+	b.WriteString("\n")
+	if hint := backend.Explain(m.err); hint != "" {
+		b.WriteString(indent.Render(metaStyle().Render(hint)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	footer := footerKeyStyle().Render("q") + " " + footerDescStyle().Render("quit")
+	if m.retry != nil {
+		footer = footerKeyStyle().Render("r") + " " + footerDescStyle().Render("retry") + "  " + footer
+	}
+	b.WriteString(indent.Render(footer))
 	b.WriteString("\n")
 
 	return b.String()
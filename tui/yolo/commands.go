@@ -1,12 +1,14 @@
 package yolo
 
 import (
+	"context"
 	"fmt"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/dinoDanic/diny/commit"
 	"github.com/dinoDanic/diny/config"
 	"github.com/dinoDanic/diny/git"
+	"github.com/dinoDanic/diny/summarize"
 )
 
 func loadRepoInfo() tea.Cmd {
@@ -43,11 +45,23 @@ func loadDiffAndGenerate(cfg *config.Config) tea.Cmd {
 			return nothingToCommitMsg{}
 		}
 
-		msg, err := commit.CreateCommitMessage(diff, cfg)
+		// A push that failed last time leaves the same diff behind; reuse
+		// the message instead of asking again.
+		if msg, ok := commit.CachedCommitMessage(diff, cfg); ok {
+			return generateDoneMsg{commitMessage: commit.ApplyIssue(msg, cfg.Commit.Issue, commit.BranchIssueKey(cfg))}
+		}
+
+		prompt, err := summarize.Condense(context.Background(), diff, cfg, nil)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to summarise large diff: %w", err)}
+		}
+
+		msg, err := commit.CreateCommitMessage(context.Background(), prompt, cfg)
 		if err != nil {
 			return errMsg{err: fmt.Errorf("failed to generate commit message: %w", err)}
 		}
-		return generateDoneMsg{commitMessage: msg}
+		commit.CacheCommitMessage(diff, cfg, msg)
+		return generateDoneMsg{commitMessage: commit.ApplyIssue(msg, cfg.Commit.Issue, commit.BranchIssueKey(cfg))}
 	}
 }
 
@@ -1,6 +1,7 @@
 package yolo
 
 import (
+	"fmt"
 	"github.com/dinoDanic/diny/config"
 	"github.com/dinoDanic/diny/git"
 	"github.com/dinoDanic/diny/tui/loader"
@@ -17,6 +18,22 @@ const (
 	stateError
 )
 
+var stateNames = map[state]string{
+	stateStaging:         "staging",
+	stateGenerating:      "generating",
+	stateCommitting:      "committing",
+	stateSuccess:         "success",
+	stateNothingToCommit: "nothing-to-commit",
+	stateError:           "error",
+}
+
+func (s state) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("state(%d)", int(s))
+}
+
 // Messages
 
 type repoInfoMsg struct {
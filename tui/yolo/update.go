@@ -2,6 +2,7 @@ package yolo
 
 import (
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dinoDanic/diny/logging"
 	"github.com/dinoDanic/diny/tui/loader"
 )
 
@@ -14,7 +15,20 @@ func (m model) Init() tea.Cmd {
 	)
 }
 
+// Update logs state transitions around update, which does the work.
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if n, ok := next.(model); ok && n.state != m.state {
+		args := []any{"tui", "yolo", "from", m.state, "to", n.state}
+		if n.state == stateError && n.err != nil {
+			args = append(args, "error", logging.Scrub(n.err.Error()))
+		}
+		logging.Info("state", args...)
+	}
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -128,7 +128,7 @@ func (m model) renderStagedFiles() string {
 		case "D":
 			icon = "-"
 			style = fileDeletedStyle()
-		case "R":
+		case "R", "C":
 			icon = ">"
 			style = fileRenamedStyle()
 		default: